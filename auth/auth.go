@@ -0,0 +1,144 @@
+package auth
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// identity is who is calling - what middleware and tools look at to decide what's allowed
+type Identity struct {
+	Subject string         // API key name or the token's "sub" claim
+	Roles   []string       // what the caller may do; interpreted by the authorization policy
+	Method  string         // "api_key" or "jwt"
+	Claims  map[string]any // every claim of a JWT; nil for API keys
+}
+
+// identityKey is how an Identity travels in a context
+type identityKey struct{}
+
+// withIdentity returns a context carrying the caller's identity
+func WithIdentity(ctx context.Context, id Identity) context.Context {
+	return context.WithValue(ctx, identityKey{}, id)
+}
+
+// fromContext returns the caller's identity; ok is false for unauthenticated calls (stdio, or auth off)
+func FromContext(ctx context.Context) (Identity, bool) {
+	id, ok := ctx.Value(identityKey{}).(Identity)
+	return id, ok
+}
+
+// apiKey is one accepted static key; we only ever hold its hash
+type APIKey struct {
+	Name   string   // becomes Identity.Subject - say who the key belongs to
+	SHA256 [32]byte // sha256 of the key itself
+	Roles  []string
+}
+
+// hashKey is how keys are stored and compared: `printf %s "$KEY" | sha256sum`
+func HashKey(key string) [32]byte {
+	return sha256.Sum256([]byte(key))
+}
+
+// parseKeyHash reads a hex sha256 as printed by sha256sum
+func ParseKeyHash(s string) ([32]byte, error) {
+	var sum [32]byte
+	b, err := hex.DecodeString(strings.TrimSpace(s))
+	if err != nil || len(b) != len(sum) {
+		return sum, fmt.Errorf("want 64 hex characters (a sha256 digest)")
+	}
+	copy(sum[:], b)
+	return sum, nil
+}
+
+// options configures an Authenticator; leave JWKSFile empty to accept API keys only
+type Options struct {
+	APIKeys []APIKey
+
+	JWKSFile   string        // local JSON Web Key Set used to verify bearer tokens
+	Issuer     string        // required "iss"; empty accepts any issuer
+	Audience   string        // required entry in "aud"; empty accepts any audience
+	RolesClaim string        // claim holding the caller's roles (a list, or a space-separated string)
+	Leeway     time.Duration // clock skew tolerated on exp and nbf
+}
+
+// authenticator checks the credentials on incoming HTTP requests
+type Authenticator struct {
+	keys []APIKey
+	jwt  *jwtVerifier // nil when no JWKS is configured
+}
+
+// errUnauthenticated means no credentials were presented at all
+var ErrUnauthenticated = errors.New("missing credentials")
+
+// new builds an Authenticator, loading the JWKS file if there is one
+func New(opts Options) (*Authenticator, error) {
+	a := &Authenticator{keys: opts.APIKeys}
+	if opts.JWKSFile != "" {
+		v, err := newJWTVerifier(opts)
+		if err != nil {
+			return nil, err
+		}
+		a.jwt = v
+	}
+	return a, nil
+}
+
+// authenticate works out who sent a request
+// credentials come as "Authorization: Bearer <JWT or API key>" or "X-API-Key: <key>"
+func (a *Authenticator) Authenticate(r *http.Request) (Identity, error) {
+	if key := r.Header.Get("X-API-Key"); key != "" {
+		return a.checkKey(key)
+	}
+
+	scheme, token, ok := strings.Cut(r.Header.Get("Authorization"), " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") || token == "" {
+		return Identity{}, ErrUnauthenticated
+	}
+	if strings.Count(token, ".") == 2 && a.jwt != nil {
+		return a.jwt.verify(token, time.Now())
+	}
+	return a.checkKey(token)
+}
+
+// checkKey finds the API key a caller presented
+// every configured key is compared, in constant time, so timing says nothing about near misses
+func (a *Authenticator) checkKey(key string) (Identity, error) {
+	sum := HashKey(key)
+	var match *APIKey
+	for i := range a.keys {
+		if subtle.ConstantTimeCompare(sum[:], a.keys[i].SHA256[:]) == 1 {
+			match = &a.keys[i]
+		}
+	}
+	if match == nil {
+		return Identity{}, errors.New("unknown API key")
+	}
+	return Identity{Subject: match.Name, Roles: match.Roles, Method: "api_key"}, nil
+}
+
+// middleware rejects unauthenticated requests with 401 and hands the caller's identity down the context
+func (a *Authenticator) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id, err := a.Authenticate(r)
+		if err != nil {
+			slog.WarnContext(r.Context(), "request rejected: authentication failed",
+				"component", "auth", "remote_addr", r.RemoteAddr, "path", r.URL.Path, "error", err)
+			challenge := `Bearer realm="mcp"`
+			if !errors.Is(err, ErrUnauthenticated) {
+				challenge += `, error="invalid_token"`
+			}
+			w.Header().Set("WWW-Authenticate", challenge)
+			http.Error(w, "unauthorized", http.StatusUnauthorized) // say no more - details are in our log
+			return
+		}
+		next.ServeHTTP(w, r.WithContext(WithIdentity(r.Context(), id)))
+	})
+}
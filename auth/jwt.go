@@ -0,0 +1,349 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	_ "crypto/sha256" // registers SHA-256 for crypto.Hash
+	_ "crypto/sha512" // registers SHA-384 and SHA-512
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math/big"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// jwksRecheck is how often we look at the JWKS file for rotated keys
+const jwksRecheck = 30 * time.Second
+
+// jwtVerifier checks bearer tokens against a local JSON Web Key Set
+// the file is re-read when it changes, so rotating keys doesn't need a restart
+type jwtVerifier struct {
+	opts Options
+
+	mu        sync.Mutex
+	keys      map[string]crypto.PublicKey // by kid
+	modTime   time.Time
+	lastCheck time.Time
+}
+
+func newJWTVerifier(opts Options) (*jwtVerifier, error) {
+	if opts.RolesClaim == "" {
+		opts.RolesClaim = "roles"
+	}
+	v := &jwtVerifier{opts: opts}
+	if err := v.reload(); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+// reload reads the JWKS file if it changed since last time; callers hold v.mu (or own v outright)
+func (v *jwtVerifier) reload() error {
+	info, err := os.Stat(v.opts.JWKSFile)
+	if err != nil {
+		return fmt.Errorf("jwks: %w", err)
+	}
+	if v.keys != nil && info.ModTime().Equal(v.modTime) {
+		return nil
+	}
+
+	data, err := os.ReadFile(v.opts.JWKSFile)
+	if err != nil {
+		return fmt.Errorf("jwks: %w", err)
+	}
+	keys, err := parseJWKS(data)
+	if err != nil {
+		return fmt.Errorf("jwks %s: %w", v.opts.JWKSFile, err)
+	}
+	v.keys, v.modTime = keys, info.ModTime()
+	return nil
+}
+
+// key finds the verification key for a token, picking up a rotated JWKS file if there is one
+func (v *jwtVerifier) key(kid string, now time.Time) (crypto.PublicKey, error) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	if now.Sub(v.lastCheck) > jwksRecheck {
+		v.lastCheck = now
+		if err := v.reload(); err != nil {
+			// a half-written or deleted file shouldn't lock everyone out - keep the keys we have
+			slog.Warn("keeping previous JWKS", "component", "auth", "error", err)
+		}
+	}
+
+	if kid == "" && len(v.keys) == 1 {
+		for _, k := range v.keys {
+			return k, nil // a single-key set doesn't need kids
+		}
+	}
+	k, ok := v.keys[kid]
+	if !ok {
+		return nil, fmt.Errorf("no key with kid %q", kid)
+	}
+	return k, nil
+}
+
+// verify checks a compact JWT's signature and claims and turns it into an Identity
+func (v *jwtVerifier) verify(token string, now time.Time) (Identity, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return Identity{}, errors.New("malformed token")
+	}
+
+	var header struct {
+		Alg string `json:"alg"`
+		Kid string `json:"kid"`
+	}
+	if err := decodeSegment(parts[0], &header); err != nil {
+		return Identity{}, fmt.Errorf("token header: %w", err)
+	}
+	sig, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return Identity{}, errors.New("token signature is not base64url")
+	}
+	key, err := v.key(header.Kid, now)
+	if err != nil {
+		return Identity{}, err
+	}
+	if err := verifySignature(header.Alg, key, parts[0]+"."+parts[1], sig); err != nil {
+		return Identity{}, err
+	}
+
+	var claims map[string]any
+	if err := decodeSegment(parts[1], &claims); err != nil {
+		return Identity{}, fmt.Errorf("token claims: %w", err)
+	}
+	if err := v.checkClaims(claims, now); err != nil {
+		return Identity{}, err
+	}
+
+	sub, _ := claims["sub"].(string)
+	if sub == "" {
+		return Identity{}, errors.New("token has no subject")
+	}
+	return Identity{Subject: sub, Roles: stringList(claims[v.opts.RolesClaim]), Method: "jwt", Claims: claims}, nil
+}
+
+// checkClaims enforces expiry, not-before, issuer and audience
+func (v *jwtVerifier) checkClaims(claims map[string]any, now time.Time) error {
+	exp, ok := claims["exp"].(float64)
+	if !ok {
+		return errors.New("token has no expiry") // tokens that never expire are keys in disguise
+	}
+	if now.After(time.Unix(int64(exp), 0).Add(v.opts.Leeway)) {
+		return errors.New("token expired")
+	}
+	if nbf, ok := claims["nbf"].(float64); ok && now.Add(v.opts.Leeway).Before(time.Unix(int64(nbf), 0)) {
+		return errors.New("token not valid yet")
+	}
+	if v.opts.Issuer != "" {
+		if iss, _ := claims["iss"].(string); iss != v.opts.Issuer {
+			return fmt.Errorf("token issuer %q not accepted", iss)
+		}
+	}
+	if v.opts.Audience != "" && !slices.Contains(stringList(claims["aud"]), v.opts.Audience) {
+		return errors.New("token audience not accepted")
+	}
+	return nil
+}
+
+// esCurves is the one curve each ECDSA algorithm may be used with
+var esCurves = map[string]elliptic.Curve{
+	"ES256": elliptic.P256(),
+	"ES384": elliptic.P384(),
+	"ES512": elliptic.P521(),
+}
+
+// verifySignature checks sig over signed with the algorithm the token names,
+// provided the key is of the matching type - "none" and HMAC are never accepted
+func verifySignature(alg string, key crypto.PublicKey, signed string, sig []byte) error {
+	var hash crypto.Hash
+	switch alg {
+	case "RS256", "PS256", "ES256":
+		hash = crypto.SHA256
+	case "RS384", "PS384", "ES384":
+		hash = crypto.SHA384
+	case "RS512", "PS512", "ES512":
+		hash = crypto.SHA512
+	case "EdDSA":
+	default:
+		return fmt.Errorf("token algorithm %q not accepted", alg)
+	}
+
+	invalid := errors.New("invalid token signature")
+	switch k := key.(type) {
+	case *rsa.PublicKey:
+		if alg[:2] != "RS" && alg[:2] != "PS" {
+			break // checked before hashing - EdDSA has no hash, and asking for one panics
+		}
+		h := hash.New()
+		h.Write([]byte(signed))
+		switch alg[:2] {
+		case "RS":
+			if rsa.VerifyPKCS1v15(k, hash, h.Sum(nil), sig) != nil {
+				return invalid
+			}
+			return nil
+		case "PS":
+			if rsa.VerifyPSS(k, hash, h.Sum(nil), sig, nil) != nil {
+				return invalid
+			}
+			return nil
+		}
+	case *ecdsa.PublicKey:
+		if alg[:2] != "ES" || k.Curve != esCurves[alg] {
+			break // each ES algorithm names its curve (RFC 7518 section 3.4), not just a hash
+		}
+		size := (k.Curve.Params().BitSize + 7) / 8
+		if len(sig) != 2*size {
+			return invalid
+		}
+		h := hash.New()
+		h.Write([]byte(signed))
+		r, s := new(big.Int).SetBytes(sig[:size]), new(big.Int).SetBytes(sig[size:])
+		if !ecdsa.Verify(k, h.Sum(nil), r, s) {
+			return invalid
+		}
+		return nil
+	case ed25519.PublicKey:
+		if alg != "EdDSA" {
+			break
+		}
+		if !ed25519.Verify(k, []byte(signed), sig) {
+			return invalid
+		}
+		return nil
+	}
+	return fmt.Errorf("token algorithm %q does not match its key", alg)
+}
+
+// jwk is the subset of RFC 7517 we need for RSA, EC and Ed25519 public keys
+type jwk struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+// parseJWKS decodes a key set, skipping encryption keys and key types we don't know
+func parseJWKS(data []byte) (map[string]crypto.PublicKey, error) {
+	var set struct {
+		Keys []jwk `json:"keys"`
+	}
+	if err := json.Unmarshal(data, &set); err != nil {
+		return nil, err
+	}
+
+	keys := make(map[string]crypto.PublicKey, len(set.Keys))
+	for i, k := range set.Keys {
+		if k.Use == "enc" {
+			continue
+		}
+		pub, err := k.publicKey()
+		if err != nil {
+			return nil, fmt.Errorf("key %d (kid %q): %w", i, k.Kid, err)
+		}
+		if pub != nil {
+			keys[k.Kid] = pub
+		}
+	}
+	if len(keys) == 0 {
+		return nil, errors.New("no usable signing keys")
+	}
+	return keys, nil
+}
+
+func (k jwk) publicKey() (crypto.PublicKey, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := decodeBigInt(k.N)
+		if err != nil {
+			return nil, fmt.Errorf("n: %w", err)
+		}
+		if n.BitLen() < 2048 {
+			return nil, fmt.Errorf("RSA key is only %d bits (want at least 2048)", n.BitLen())
+		}
+		e, err := decodeBigInt(k.E)
+		if err != nil || !e.IsInt64() {
+			return nil, errors.New("e: invalid exponent")
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+
+	case "EC":
+		var curve elliptic.Curve
+		switch k.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("unsupported curve %q", k.Crv)
+		}
+		x, errX := decodeBigInt(k.X)
+		y, errY := decodeBigInt(k.Y)
+		if errX != nil || errY != nil || !curve.IsOnCurve(x, y) {
+			return nil, errors.New("invalid EC point")
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+
+	case "OKP":
+		if k.Crv != "Ed25519" {
+			return nil, fmt.Errorf("unsupported curve %q", k.Crv)
+		}
+		x, err := base64.RawURLEncoding.DecodeString(k.X)
+		if err != nil || len(x) != ed25519.PublicKeySize {
+			return nil, errors.New("invalid Ed25519 key")
+		}
+		return ed25519.PublicKey(x), nil
+	}
+	return nil, nil // symmetric or unknown key types can't verify anything we accept
+}
+
+func decodeSegment(seg string, v any) error {
+	b, err := base64.RawURLEncoding.DecodeString(seg)
+	if err != nil {
+		return errors.New("not base64url")
+	}
+	return json.Unmarshal(b, v)
+}
+
+func decodeBigInt(s string) (*big.Int, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil || len(b) == 0 {
+		return nil, errors.New("not base64url")
+	}
+	return new(big.Int).SetBytes(b), nil
+}
+
+// stringList reads a claim that may be a single string, a space-separated string or a list
+func stringList(v any) []string {
+	switch v := v.(type) {
+	case string:
+		return strings.Fields(v)
+	case []any:
+		out := make([]string, 0, len(v))
+		for _, item := range v {
+			if s, ok := item.(string); ok {
+				out = append(out, s)
+			}
+		}
+		return out
+	}
+	return nil
+}
@@ -0,0 +1,164 @@
+package auth
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// grants lists what one role may use; entries are globs where * matches anything, "/" included
+type Grants struct {
+	Tools     []string `yaml:"tools" json:"tools"`         // tool names
+	Resources []string `yaml:"resources" json:"resources"` // resource URIs or URI templates, e.g. "docs://*"
+	Prompts   []string `yaml:"prompts" json:"prompts"`     // prompt names
+}
+
+// policyFile is the on-disk shape of a Policy
+type policyFile struct {
+	Roles     map[string]Grants   `yaml:"roles" json:"roles"`         // what each role grants
+	Subjects  map[string][]string `yaml:"subjects" json:"subjects"`   // extra roles for callers named "api_key:<name>" or "jwt:<sub>", on top of their credential's
+	Anonymous []string            `yaml:"anonymous" json:"anonymous"` // roles for callers without an identity (stdio, or auth off)
+}
+
+// policy decides who may use which tools, resources and prompts
+// anything not granted is denied - an empty policy locks everything away
+type Policy struct {
+	roles     map[string]compiledGrants
+	subjects  map[string][]string // by subjectKey
+	anonymous []string
+}
+
+// subjectKey names a caller by how they authenticated as well as who they are
+// an API key called "alice" and a token with sub "alice" are different callers,
+// and neither should pick up the other's roles
+func subjectKey(method, subject string) string {
+	return method + ":" + subject
+}
+
+// subjectMethods are the prefixes a subjects entry may use - Identity.Method values
+var subjectMethods = []string{"api_key", "jwt"}
+
+type compiledGrants struct {
+	tools, resources, prompts []*regexp.Regexp
+}
+
+// loadPolicy reads a policy from a .yaml, .yml or .json file, refusing unknown keys
+func LoadPolicy(path string) (*Policy, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read policy: %w", err)
+	}
+
+	var f policyFile
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".yaml", ".yml":
+		dec := yaml.NewDecoder(bytes.NewReader(data))
+		dec.KnownFields(true)
+		if err := dec.Decode(&f); err != nil && !errors.Is(err, io.EOF) {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+	case ".json":
+		dec := json.NewDecoder(bytes.NewReader(data))
+		dec.DisallowUnknownFields()
+		if err := dec.Decode(&f); err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+	default:
+		return nil, fmt.Errorf("%s: unsupported policy format %q (want .yaml, .yml or .json)", path, ext)
+	}
+
+	p, err := f.compile()
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return p, nil
+}
+
+// compile checks the role references and turns every glob into a regexp once, up front
+func (f policyFile) compile() (*Policy, error) {
+	var errs []error
+	p := &Policy{roles: make(map[string]compiledGrants, len(f.Roles)), subjects: f.Subjects, anonymous: f.Anonymous}
+	for name, g := range f.Roles {
+		p.roles[name] = compiledGrants{
+			tools:     compileGlobs(g.Tools),
+			resources: compileGlobs(g.Resources),
+			prompts:   compileGlobs(g.Prompts),
+		}
+	}
+
+	// a typo in a role name would silently grant nothing - say so instead
+	check := func(where string, roles []string) {
+		for _, r := range roles {
+			if _, ok := f.Roles[r]; !ok {
+				errs = append(errs, fmt.Errorf("%s: unknown role %q", where, r))
+			}
+		}
+	}
+	for subject, roles := range f.Subjects {
+		method, name, _ := strings.Cut(subject, ":")
+		if !slices.Contains(subjectMethods, method) || name == "" {
+			errs = append(errs, fmt.Errorf("subjects.%s: want \"api_key:<key name>\" or \"jwt:<sub>\"", subject))
+			continue
+		}
+		check("subjects."+subject, roles)
+	}
+	check("anonymous", f.Anonymous)
+	if err := errors.Join(errs...); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// compileGlobs turns "*"-globs into anchored regexps
+func compileGlobs(globs []string) []*regexp.Regexp {
+	out := make([]*regexp.Regexp, len(globs))
+	for i, g := range globs {
+		parts := strings.Split(g, "*")
+		for j := range parts {
+			parts[j] = regexp.QuoteMeta(parts[j])
+		}
+		out[i] = regexp.MustCompile("^" + strings.Join(parts, ".*") + "$") // quoted input always compiles
+	}
+	return out
+}
+
+// roles returns every role that applies to a caller
+func (p *Policy) rolesFor(id Identity, ok bool) []string {
+	if !ok {
+		return p.anonymous
+	}
+	return append(slices.Clone(id.Roles), p.subjects[subjectKey(id.Method, id.Subject)]...)
+}
+
+// allows reports whether a caller may use a capability
+// kind is "tool", "resource" or "prompt"; name is the tool/prompt name or resource URI
+func (p *Policy) Allows(id Identity, authenticated bool, kind, name string) bool {
+	for _, role := range p.rolesFor(id, authenticated) {
+		g, ok := p.roles[role]
+		if !ok {
+			continue // roles from a credential that the policy doesn't know grant nothing
+		}
+		var globs []*regexp.Regexp
+		switch kind {
+		case "tool":
+			globs = g.tools
+		case "resource":
+			globs = g.resources
+		case "prompt":
+			globs = g.prompts
+		}
+		if slices.ContainsFunc(globs, func(re *regexp.Regexp) bool { return re.MatchString(name) }) {
+			return true
+		}
+	}
+	return false
+}
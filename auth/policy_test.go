@@ -0,0 +1,89 @@
+package auth
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writePolicy(t *testing.T, name, body string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(body), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestPolicyAllows(t *testing.T) {
+	p, err := LoadPolicy(writePolicy(t, "policy.yaml", `
+roles:
+  admin: {tools: ["*"], resources: ["*"], prompts: ["*"]}
+  ops:   {tools: [server_stats], resources: ["metrics://*"]}
+  docs:  {resources: ["docs://*", "file://README.md"]}
+subjects:
+  jwt:alice: [admin]
+  api_key:ci-bot: [ops]
+anonymous: [docs]
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	alice := Identity{Subject: "alice", Method: "jwt"}
+	aliceKey := Identity{Subject: "alice", Method: "api_key"}
+	ciBot := Identity{Subject: "ci-bot", Method: "api_key"}
+	ciBotToken := Identity{Subject: "ci-bot", Method: "jwt"}
+	withRole := Identity{Subject: "bob", Method: "jwt", Roles: []string{"ops"}}
+
+	tests := []struct {
+		name          string
+		id            Identity
+		authenticated bool
+		kind, target  string
+		want          bool
+	}{
+		{"subject grant", alice, true, "tool", "delete_everything", true},
+		{"API key named like a JWT subject gets nothing", aliceKey, true, "tool", "delete_everything", false},
+		{"API key subject grant", ciBot, true, "tool", "server_stats", true},
+		{"token named like an API key gets nothing", ciBotToken, true, "tool", "server_stats", false},
+		{"roles from the credential", withRole, true, "resource", "metrics://server", true},
+		{"glob stays inside its grant", withRole, true, "resource", "docs://readme", false},
+		{"kinds don't mix", withRole, true, "prompt", "server_stats", false},
+		{"anonymous roles", Identity{}, false, "resource", "file://README.md", true},
+		{"anonymous denied the rest", Identity{}, false, "tool", "server_stats", false},
+		{"an authenticated caller doesn't get anonymous roles", Identity{Subject: "nobody", Method: "jwt"}, true, "resource", "docs://readme", false},
+		{"unknown credential roles grant nothing", Identity{Subject: "eve", Method: "jwt", Roles: []string{"root"}}, true, "tool", "server_stats", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := p.Allows(tt.id, tt.authenticated, tt.kind, tt.target); got != tt.want {
+				t.Errorf("Allows(%+v, %s %s) = %v, want %v", tt.id, tt.kind, tt.target, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadPolicyRejects(t *testing.T) {
+	tests := []struct {
+		name, file, body, want string
+	}{
+		{"subject without a method", "p.yaml", "roles: {admin: {}}\nsubjects: {alice: [admin]}\n", `subjects.alice: want "api_key:<key name>" or "jwt:<sub>"`},
+		{"subject with an unknown method", "p.yaml", "roles: {admin: {}}\nsubjects: {\"oauth:alice\": [admin]}\n", "subjects.oauth:alice"},
+		{"subject with no name", "p.yaml", "roles: {admin: {}}\nsubjects: {\"jwt:\": [admin]}\n", "subjects.jwt:"},
+		{"unknown role for a subject", "p.yaml", "roles: {admin: {}}\nsubjects: {\"jwt:alice\": [amdin]}\n", `subjects.jwt:alice: unknown role "amdin"`},
+		{"unknown anonymous role", "p.yaml", "roles: {admin: {}}\nanonymous: [guest]\n", `anonymous: unknown role "guest"`},
+		{"unknown YAML key", "p.yaml", "roles: {}\nsubject: {}\n", "field subject not found"},
+		{"unknown JSON key", "p.json", `{"roles": {}, "subject": {}}`, `unknown field "subject"`},
+		{"unsupported format", "p.toml", "", "unsupported policy format"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := LoadPolicy(writePolicy(t, tt.file, tt.body))
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("LoadPolicy error = %v, want it to mention %q", err, tt.want)
+			}
+		})
+	}
+}
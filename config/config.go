@@ -0,0 +1,420 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+// config is everything ops can change about a deployment without recompiling
+// it starts from Default(), then the config file, then HELLO_MCP_* env vars win
+type Config struct {
+	Server        ServerConfig        `yaml:"server" json:"server" toml:"server"`
+	Transport     TransportConfig     `yaml:"transport" json:"transport" toml:"transport"`
+	Log           LogConfig           `yaml:"log" json:"log" toml:"log"`
+	Tools         Capabilities        `yaml:"tools" json:"tools" toml:"tools"`
+	Resources     Capabilities        `yaml:"resources" json:"resources" toml:"resources"`
+	Prompts       Capabilities        `yaml:"prompts" json:"prompts" toml:"prompts"`
+	Static        StaticConfig        `yaml:"static" json:"static" toml:"static"`
+	Files         FilesConfig         `yaml:"files" json:"files" toml:"files"`
+	Subscriptions SubscriptionsConfig `yaml:"subscriptions" json:"subscriptions" toml:"subscriptions"`
+	Middleware    MiddlewareConfig    `yaml:"middleware" json:"middleware" toml:"middleware"`
+	Metrics       MetricsConfig       `yaml:"metrics" json:"metrics" toml:"metrics"`
+	Tracing       TracingConfig       `yaml:"tracing" json:"tracing" toml:"tracing"`
+	Auth          AuthConfig          `yaml:"auth" json:"auth" toml:"auth"`
+}
+
+// serverConfig drives the options we hand to server.NewMCPServer
+type ServerConfig struct {
+	Name                string `yaml:"name" json:"name" toml:"name"`                                                    // what clients see in serverInfo
+	Version             string `yaml:"version" json:"version" toml:"version"`                                           // semver, ideally
+	Instructions        string `yaml:"instructions" json:"instructions" toml:"instructions"`                            // optional hint text for the client's model
+	Logging             bool   `yaml:"logging" json:"logging" toml:"logging"`                                           // enable the SDK's logging capability
+	ToolListChanged     bool   `yaml:"tool_list_changed" json:"tool_list_changed" toml:"tool_list_changed"`             // advertise tools/list_changed notifications
+	ResourceSubscribe   bool   `yaml:"resource_subscribe" json:"resource_subscribe" toml:"resource_subscribe"`          // advertise resources/subscribe
+	ResourceListChanged bool   `yaml:"resource_list_changed" json:"resource_list_changed" toml:"resource_list_changed"` // advertise resources/list_changed notifications
+	PromptListChanged   bool   `yaml:"prompt_list_changed" json:"prompt_list_changed" toml:"prompt_list_changed"`       // advertise prompts/list_changed notifications
+}
+
+// transportConfig mirrors transport.Options in a file-friendly shape
+type TransportConfig struct {
+	Mode            string   `yaml:"mode" json:"mode" toml:"mode"`                                     // stdio, sse or http
+	Addr            string   `yaml:"addr" json:"addr" toml:"addr"`                                     // listen address for network transports
+	BasePath        string   `yaml:"base_path" json:"base_path" toml:"base_path"`                      // URL prefix for network transports
+	ShutdownTimeout Duration `yaml:"shutdown_timeout" json:"shutdown_timeout" toml:"shutdown_timeout"` // grace period on shutdown
+}
+
+// logConfig says where our diary gets written
+type LogConfig struct {
+	Path   string `yaml:"path" json:"path" toml:"path"`       // a file path, or "stderr" (never stdout - that's the protocol's!)
+	Format string `yaml:"format" json:"format" toml:"format"` // text or json
+	Level  string `yaml:"level" json:"level" toml:"level"`    // debug, info, warn or error
+}
+
+// capabilities picks which tools, resources or prompts get registered
+// an empty Enabled list means "everything", Disabled always wins
+type Capabilities struct {
+	Enabled  []string `yaml:"enabled" json:"enabled" toml:"enabled"`    // allowlist by name (or URI for resources)
+	Disabled []string `yaml:"disabled" json:"disabled" toml:"disabled"` // denylist by name (or URI for resources)
+	Tags     []string `yaml:"tags" json:"tags" toml:"tags"`             // only register capabilities carrying one of these tags
+}
+
+// staticConfig controls the files served as resources, one per file
+// they're compiled into the binary; Dir layers an on-disk directory over them
+type StaticConfig struct {
+	Dir       string `yaml:"dir" json:"dir" toml:"dir"`                      // override directory: its files replace and add to the bundled ones; empty serves only the bundled files
+	URIPrefix string `yaml:"uri_prefix" json:"uri_prefix" toml:"uri_prefix"` // prepended to each file's slash path to form its URI
+}
+
+// filesConfig exposes a directory through the file:///{+path} resource template
+type FilesConfig struct {
+	Root string `yaml:"root" json:"root" toml:"root"` // sandbox root; nothing outside it can be read. empty turns the template off
+}
+
+// subscriptionsConfig tunes change notifications for server.resource_subscribe
+type SubscriptionsConfig struct {
+	PollInterval Duration `yaml:"poll_interval" json:"poll_interval" toml:"poll_interval"` // how often subscribed files are checked
+	Debounce     Duration `yaml:"debounce" json:"debounce" toml:"debounce"`                // quiet time after the last write before subscribers are told
+}
+
+// middlewareConfig toggles the individual layers of the middleware stack
+type MiddlewareConfig struct {
+	Logging               bool                    `yaml:"logging" json:"logging" toml:"logging"`
+	Recovery              bool                    `yaml:"recovery" json:"recovery" toml:"recovery"`
+	Metrics               bool                    `yaml:"metrics" json:"metrics" toml:"metrics"`
+	Validation            bool                    `yaml:"validation" json:"validation" toml:"validation"`                                        // check tool arguments against their input schema
+	RedactArgs            []string                `yaml:"redact_args" json:"redact_args" toml:"redact_args"`                                     // extra argument name patterns to mask, on top of the built-ins
+	MaxLoggedArgLength    int                     `yaml:"max_logged_arg_length" json:"max_logged_arg_length" toml:"max_logged_arg_length"`       // truncate logged strings past this many bytes (0 = never)
+	ToolTimeout           Duration                `yaml:"tool_timeout" json:"tool_timeout" toml:"tool_timeout"`                                  // deadline for tools that don't declare one (0 = none)
+	ToolTimeouts          map[string]Duration     `yaml:"tool_timeouts" json:"tool_timeouts" toml:"tool_timeouts"`                               // per-tool deadlines, beating whatever the tool declares
+	DefaultToolLimits     LimitsConfig            `yaml:"default_tool_limits" json:"default_tool_limits" toml:"default_tool_limits"`             // limits for tools not listed in tool_limits
+	ToolLimits            map[string]LimitsConfig `yaml:"tool_limits" json:"tool_limits" toml:"tool_limits"`                                     // per-tool concurrency and rate limits
+	CrashDir              string                  `yaml:"crash_dir" json:"crash_dir" toml:"crash_dir"`                                           // where panics leave crash reports (empty = log only)
+	OutputValidation      string                  `yaml:"output_validation" json:"output_validation" toml:"output_validation"`                   // off, warn or strict: check tool results against their output schema
+	ResourceConsistency   string                  `yaml:"resource_consistency" json:"resource_consistency" toml:"resource_consistency"`          // off, warn or strict: check resource contents carry the URI read and the declared MIME type
+	AllowDestructiveTools bool                    `yaml:"allow_destructive_tools" json:"allow_destructive_tools" toml:"allow_destructive_tools"` // serve tools annotated (or defaulting to) destructive
+}
+
+// limitsConfig mirrors middleware.ToolLimits; zero values mean unlimited
+type LimitsConfig struct {
+	MaxConcurrent int      `yaml:"max_concurrent" json:"max_concurrent" toml:"max_concurrent"` // calls allowed to run at once
+	QueueTimeout  Duration `yaml:"queue_timeout" json:"queue_timeout" toml:"queue_timeout"`    // how long to wait for a free slot
+	Rate          float64  `yaml:"rate" json:"rate" toml:"rate"`                               // calls per second across all clients
+	Burst         int      `yaml:"burst" json:"burst" toml:"burst"`
+	SessionRate   float64  `yaml:"session_rate" json:"session_rate" toml:"session_rate"` // calls per second per client session
+	SessionBurst  int      `yaml:"session_burst" json:"session_burst" toml:"session_burst"`
+}
+
+// metricsConfig controls the OpenMetrics side server
+// it runs on its own port so it works next to stdio too
+type MetricsConfig struct {
+	Addr    string    `yaml:"addr" json:"addr" toml:"addr"`          // listen address, e.g. ":9090"; empty disables the exporter
+	Path    string    `yaml:"path" json:"path" toml:"path"`          // URL path to serve metrics on
+	Buckets []float64 `yaml:"buckets" json:"buckets" toml:"buckets"` // latency histogram bucket bounds in seconds; empty keeps the defaults
+}
+
+// tracingConfig mirrors tracing.Options; an empty exporter leaves tracing off
+type TracingConfig struct {
+	Exporter    string  `yaml:"exporter" json:"exporter" toml:"exporter"`             // "", otlp or file
+	Endpoint    string  `yaml:"endpoint" json:"endpoint" toml:"endpoint"`             // OTLP endpoint, "host:port" or a URL
+	Insecure    bool    `yaml:"insecure" json:"insecure" toml:"insecure"`             // plain HTTP to the OTLP endpoint
+	File        string  `yaml:"file" json:"file" toml:"file"`                         // output path for the file exporter
+	SampleRatio float64 `yaml:"sample_ratio" json:"sample_ratio" toml:"sample_ratio"` // fraction of new traces to keep (0 = all)
+}
+
+// authConfig protects the network transports; with no keys and no JWKS they're open
+type AuthConfig struct {
+	APIKeys    []APIKeyConfig `yaml:"api_keys" json:"api_keys" toml:"api_keys"`
+	JWT        JWTConfig      `yaml:"jwt" json:"jwt" toml:"jwt"`
+	PolicyFile string         `yaml:"policy_file" json:"policy_file" toml:"policy_file"` // role-based authorization policy (.yaml or .json); empty allows everything
+}
+
+// apiKeyConfig is one static key; the key itself never goes in the file
+type APIKeyConfig struct {
+	Name      string   `yaml:"name" json:"name" toml:"name"`                   // who holds the key - becomes the caller's identity
+	KeySHA256 string   `yaml:"key_sha256" json:"key_sha256" toml:"key_sha256"` // hex sha256 of the key
+	KeyEnv    string   `yaml:"key_env" json:"key_env" toml:"key_env"`          // or: environment variable holding the key
+	Roles     []string `yaml:"roles" json:"roles" toml:"roles"`
+}
+
+// jwtConfig accepts bearer tokens signed by a key in a local JWKS file
+type JWTConfig struct {
+	JWKSFile   string   `yaml:"jwks_file" json:"jwks_file" toml:"jwks_file"`       // empty disables JWT auth
+	Issuer     string   `yaml:"issuer" json:"issuer" toml:"issuer"`                // required "iss" (empty = any)
+	Audience   string   `yaml:"audience" json:"audience" toml:"audience"`          // required "aud" (empty = any)
+	RolesClaim string   `yaml:"roles_claim" json:"roles_claim" toml:"roles_claim"` // claim holding the caller's roles
+	Leeway     Duration `yaml:"leeway" json:"leeway" toml:"leeway"`                // clock skew tolerated on exp/nbf
+}
+
+// enabled reports whether any credentials are configured
+func (a AuthConfig) Enabled() bool {
+	return len(a.APIKeys) > 0 || a.JWT.JWKSFile != ""
+}
+
+// allows reports whether a capability should be registered
+// explicit names beat tags, and tags beat the capability's own default
+func (c Capabilities) Allows(name string, tags []string, enabledByDefault bool) bool {
+	if slices.Contains(c.Disabled, name) {
+		return false
+	}
+	if slices.Contains(c.Enabled, name) {
+		return true // naming something explicitly turns on even dark-launched capabilities
+	}
+	if len(c.Enabled) > 0 {
+		return false
+	}
+	if len(c.Tags) > 0 && !slices.ContainsFunc(tags, func(t string) bool { return slices.Contains(c.Tags, t) }) {
+		return false
+	}
+	return enabledByDefault
+}
+
+// duration is a time.Duration that reads and writes as "10s" in every format
+// JSON has no native duration type, so we teach it one
+type Duration struct {
+	time.Duration
+}
+
+// unmarshalText parses Go duration syntax like "1m30s"
+func (d *Duration) UnmarshalText(text []byte) error {
+	v, err := time.ParseDuration(string(text))
+	if err != nil {
+		return err
+	}
+	d.Duration = v
+	return nil
+}
+
+// unmarshalYAML is UnmarshalText plus the line, so a typo in a long file is easy to find
+// yaml.v3 passes our error through as-is, and a bare "invalid duration" doesn't say where
+func (d *Duration) UnmarshalYAML(value *yaml.Node) error {
+	if err := d.UnmarshalText([]byte(value.Value)); err != nil {
+		return &yamlDurationError{line: value.Line, value: value.Value}
+	}
+	return nil
+}
+
+// unmarshalJSON reports a bad duration as a type error, like any other wrong value
+// encoding/json never fills in Field for an Unmarshaler's error, so decodeFile finds the key
+func (d *Duration) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		return nil
+	}
+	var s string
+	if json.Unmarshal(data, &s) != nil || d.UnmarshalText([]byte(s)) != nil {
+		return &json.UnmarshalTypeError{Value: string(data), Type: reflect.TypeFor[Duration]()}
+	}
+	return nil
+}
+
+// yamlDurationError is a bad duration in a YAML file - the node knows its line but not its key
+type yamlDurationError struct {
+	line  int
+	value string
+}
+
+func (e *yamlDurationError) Error() string {
+	return fmt.Sprintf("yaml: line %d: invalid duration %q (want Go syntax like \"10s\" or \"1m30s\")", e.line, e.value)
+}
+
+// marshalText writes the duration back out in the same syntax
+func (d Duration) MarshalText() ([]byte, error) {
+	return []byte(d.String()), nil
+}
+
+// default returns the settings the server used before config files existed
+// a missing config file should never change behavior
+func Default() *Config {
+	return &Config{
+		Server: ServerConfig{
+			Name:    "hello-mcp",
+			Version: "0.1.0",
+			Logging: true,
+		},
+		Transport: TransportConfig{
+			Mode:            "stdio",
+			Addr:            ":8080",
+			BasePath:        "/mcp",
+			ShutdownTimeout: Duration{10 * time.Second},
+		},
+		Log: LogConfig{
+			Path:   "mcp-server.log",
+			Format: "text",
+			Level:  "info",
+		},
+		Static: StaticConfig{
+			URIPrefix: "static://",
+		},
+		Subscriptions: SubscriptionsConfig{
+			PollInterval: Duration{time.Second},
+			Debounce:     Duration{500 * time.Millisecond},
+		},
+		Middleware: MiddlewareConfig{
+			Logging:             true,
+			Recovery:            true,
+			Metrics:             true,
+			Validation:          true,
+			MaxLoggedArgLength:  1024,
+			CrashDir:            "crashes",
+			OutputValidation:    "off",
+			ResourceConsistency: "warn",
+		},
+		Metrics: MetricsConfig{
+			Path: "/metrics",
+		},
+		Tracing: TracingConfig{
+			File: "traces.jsonl",
+		},
+		Auth: AuthConfig{
+			JWT: JWTConfig{
+				RolesClaim: "roles",
+				Leeway:     Duration{time.Minute},
+			},
+		},
+	}
+}
+
+// load builds the effective configuration: defaults, then the file at path
+// (if any), then environment overrides, and finally validation
+func Load(path string) (*Config, error) {
+	cfg := Default()
+
+	if path != "" {
+		if err := decodeFile(path, cfg); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := applyEnv(cfg, os.LookupEnv); err != nil {
+		return nil, err
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// decodeFile picks a decoder from the file extension and refuses unknown keys
+// a typo like "trasport:" should be an error, not a silently ignored section
+func decodeFile(path string, cfg *Config) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("read config: %w", err)
+	}
+
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".yaml", ".yml":
+		dec := yaml.NewDecoder(bytes.NewReader(data))
+		dec.KnownFields(true)
+		if err := dec.Decode(cfg); err != nil && !errors.Is(err, io.EOF) {
+			var durErr *yamlDurationError
+			if errors.As(err, &durErr) {
+				var raw map[string]any
+				if yaml.Unmarshal(data, &raw) == nil {
+					if key := badDurationKey(reflect.TypeFor[Config](), raw, "yaml", ""); key != "" {
+						return fmt.Errorf("%s: line %d: %s: invalid duration %q (want Go syntax like \"10s\" or \"1m30s\")", path, durErr.line, key, durErr.value)
+					}
+				}
+			}
+			return fmt.Errorf("%s: %w", path, err)
+		}
+	case ".json":
+		dec := json.NewDecoder(bytes.NewReader(data))
+		dec.DisallowUnknownFields()
+		if err := dec.Decode(cfg); err != nil {
+			var typeErr *json.UnmarshalTypeError
+			if errors.As(err, &typeErr) {
+				if typeErr.Type == reflect.TypeFor[Duration]() {
+					var raw map[string]any
+					if typeErr.Field == "" && json.Unmarshal(data, &raw) == nil {
+						typeErr.Field = badDurationKey(reflect.TypeFor[Config](), raw, "json", "")
+					}
+					return fmt.Errorf("%s: %s: invalid duration %s (want Go syntax like \"10s\" or \"1m30s\")", path, typeErr.Field, typeErr.Value)
+				}
+				return fmt.Errorf("%s: %s: expected %s, got %s", path, typeErr.Field, typeErr.Type, typeErr.Value)
+			}
+			return fmt.Errorf("%s: %w", path, err)
+		}
+	case ".toml":
+		md, err := toml.Decode(string(data), cfg)
+		if err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+		if undecoded := md.Undecoded(); len(undecoded) > 0 {
+			return fmt.Errorf("%s: unknown key %s", path, undecoded[0])
+		}
+	default:
+		return fmt.Errorf("%s: unsupported config format %q (want .yaml, .yml, .json or .toml)", path, ext)
+	}
+	return nil
+}
+
+// badDurationKey finds the first Duration in a generically decoded file that won't parse,
+// walking it alongside the Config type by tag, and returns its dotted key
+// the JSON and YAML decoders don't tell an Unmarshaler where it is, so this does
+func badDurationKey(t reflect.Type, v any, tag, prefix string) string {
+	if t == reflect.TypeFor[Duration]() {
+		if v == nil {
+			return ""
+		}
+		if s, ok := v.(string); ok {
+			if _, err := time.ParseDuration(s); err == nil {
+				return ""
+			}
+		}
+		return prefix
+	}
+
+	join := func(name string) string {
+		if prefix == "" {
+			return name
+		}
+		return prefix + "." + name
+	}
+	switch t.Kind() {
+	case reflect.Pointer:
+		return badDurationKey(t.Elem(), v, tag, prefix)
+	case reflect.Struct:
+		m, _ := v.(map[string]any)
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			name, _, _ := strings.Cut(field.Tag.Get(tag), ",")
+			if name == "" || name == "-" {
+				continue
+			}
+			if fv, ok := m[name]; ok {
+				if key := badDurationKey(field.Type, fv, tag, join(name)); key != "" {
+					return key
+				}
+			}
+		}
+	case reflect.Map:
+		m, _ := v.(map[string]any)
+		for _, name := range slices.Sorted(maps.Keys(m)) {
+			if key := badDurationKey(t.Elem(), m[name], tag, join(name)); key != "" {
+				return key
+			}
+		}
+	case reflect.Slice:
+		items, _ := v.([]any)
+		for i, item := range items {
+			if key := badDurationKey(t.Elem(), item, tag, fmt.Sprintf("%s[%d]", prefix, i)); key != "" {
+				return key
+			}
+		}
+	}
+	return ""
+}
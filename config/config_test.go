@@ -0,0 +1,88 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func writeConfig(t *testing.T, name, body string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(body), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestDecodeFileDurations(t *testing.T) {
+	tests := []struct {
+		name, file, body string
+		want             time.Duration
+	}{
+		{"YAML", "c.yaml", "middleware:\n  tool_timeout: 1m30s\n", 90 * time.Second},
+		{"JSON", "c.json", `{"middleware":{"tool_timeout":"1m30s"}}`, 90 * time.Second},
+		{"TOML", "c.toml", "[middleware]\ntool_timeout = \"1m30s\"\n", 90 * time.Second},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := Default()
+			if err := decodeFile(writeConfig(t, tt.file, tt.body), cfg); err != nil {
+				t.Fatal(err)
+			}
+			if cfg.Middleware.ToolTimeout.Duration != tt.want {
+				t.Errorf("tool_timeout = %v, want %v", cfg.Middleware.ToolTimeout, tt.want)
+			}
+		})
+	}
+}
+
+func TestDecodeFileBadDurationNamesKey(t *testing.T) {
+	tests := []struct {
+		name, file, body string
+		want             []string // every one must appear in the error
+	}{
+		{"YAML", "c.yaml", "server:\n  name: x\nmiddleware:\n  tool_timeout: bad\n", []string{"middleware.tool_timeout", "line 4", `"bad"`}},
+		{"YAML map entry", "c.yaml", "middleware:\n  tool_timeouts:\n    ok: 5s\n    slow: 5 minutes\n", []string{"middleware.tool_timeouts.slow", "line 4"}},
+		{"YAML number", "c.yaml", "transport:\n  shutdown_timeout: 10\n", []string{"transport.shutdown_timeout", "line 2"}},
+		{"JSON", "c.json", `{"middleware":{"tool_timeout":"bad"}}`, []string{"middleware.tool_timeout", `"bad"`}},
+		{"JSON map entry", "c.json", `{"middleware":{"tool_timeouts":{"ok":"5s","slow":"5 minutes"}}}`, []string{"middleware.tool_timeouts.slow"}},
+		{"JSON number", "c.json", `{"transport":{"shutdown_timeout":10}}`, []string{"transport.shutdown_timeout"}},
+		{"TOML", "c.toml", "[middleware]\ntool_timeout = \"bad\"\n", []string{"middleware.tool_timeout", "line 2"}},
+		{"TOML map entry", "c.toml", "[middleware.tool_timeouts]\nok = \"5s\"\nslow = \"5 minutes\"\n", []string{"middleware.tool_timeouts.slow"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := decodeFile(writeConfig(t, tt.file, tt.body), Default())
+			if err == nil {
+				t.Fatal("decodeFile accepted a bad duration")
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(err.Error(), want) {
+					t.Errorf("error %q does not mention %s", err, want)
+				}
+			}
+		})
+	}
+}
+
+func TestDecodeFileRejectsUnknownKeys(t *testing.T) {
+	tests := []struct {
+		name, file, body, want string
+	}{
+		{"YAML", "c.yaml", "trasport:\n  mode: http\n", "field trasport not found"},
+		{"JSON", "c.json", `{"trasport":{"mode":"http"}}`, `unknown field "trasport"`},
+		{"TOML", "c.toml", "[trasport]\nmode = \"http\"\n", "unknown key trasport"},
+		{"unsupported format", "c.ini", "", "unsupported config format"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := decodeFile(writeConfig(t, tt.file, tt.body), Default())
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("decodeFile error = %v, want it to mention %q", err, tt.want)
+			}
+		})
+	}
+}
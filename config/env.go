@@ -0,0 +1,108 @@
+package config
+
+import (
+	"encoding"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// envPrefix namespaces our environment variables so we don't collide with anyone else's
+// transport.shutdown_timeout becomes HELLO_MCP_TRANSPORT_SHUTDOWN_TIMEOUT
+const EnvPrefix = "HELLO_MCP_"
+
+// applyEnv walks the config struct and overrides any field whose env var is set
+// the variable names are derived from the yaml keys, so new fields get env support for free
+func applyEnv(cfg *Config, lookup func(string) (string, bool)) error {
+	return applyEnvStruct(reflect.ValueOf(cfg).Elem(), "", lookup)
+}
+
+// applyEnvStruct recurses into nested sections, building up the dotted key as it goes
+func applyEnvStruct(v reflect.Value, prefix string, lookup func(string) (string, bool)) error {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name, _, _ := strings.Cut(field.Tag.Get("yaml"), ",")
+		if name == "" || name == "-" {
+			continue
+		}
+
+		key := name
+		if prefix != "" {
+			key = prefix + "." + name
+		}
+		fv := v.Field(i)
+
+		// nested sections recurse - unless they know how to parse themselves (like Duration)
+		if fv.Kind() == reflect.Struct && !implementsTextUnmarshaler(fv) {
+			if err := applyEnvStruct(fv, key, lookup); err != nil {
+				return err
+			}
+			continue
+		}
+
+		envName := EnvPrefix + strings.ToUpper(strings.ReplaceAll(key, ".", "_"))
+		raw, ok := lookup(envName)
+		if !ok {
+			continue
+		}
+		if err := setFromString(fv, raw); err != nil {
+			return fmt.Errorf("%s (%s): %w", envName, key, err)
+		}
+	}
+	return nil
+}
+
+// implementsTextUnmarshaler reports whether the field can parse its own string form
+func implementsTextUnmarshaler(v reflect.Value) bool {
+	_, ok := v.Addr().Interface().(encoding.TextUnmarshaler)
+	return ok
+}
+
+// setFromString converts an env var value into whatever type the field wants
+// lists are comma-separated, because that's what everyone types in a shell
+func setFromString(v reflect.Value, raw string) error {
+	if u, ok := v.Addr().Interface().(encoding.TextUnmarshaler); ok {
+		return u.UnmarshalText([]byte(raw))
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(raw)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return fmt.Errorf("invalid boolean %q", raw)
+		}
+		v.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(raw, 10, v.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("invalid integer %q", raw)
+		}
+		v.SetInt(n)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(raw, v.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("invalid number %q", raw)
+		}
+		v.SetFloat(f)
+	case reflect.Slice:
+		items := reflect.MakeSlice(v.Type(), 0, 0)
+		for _, item := range strings.Split(raw, ",") {
+			if item = strings.TrimSpace(item); item == "" {
+				continue
+			}
+			elem := reflect.New(v.Type().Elem()).Elem()
+			if err := setFromString(elem, item); err != nil {
+				return err
+			}
+			items = reflect.Append(items, elem)
+		}
+		v.Set(items)
+	default:
+		return fmt.Errorf("unsupported type %s", v.Type())
+	}
+	return nil
+}
@@ -0,0 +1,209 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"path"
+	"strings"
+
+	"github.com/suramrit/hello-mcp/auth"
+	"github.com/suramrit/hello-mcp/middleware"
+	"github.com/suramrit/hello-mcp/tracing"
+)
+
+// fieldError points at the exact key that's wrong
+// "transport.mode: must be one of stdio, sse, http" beats "invalid config" every time
+type FieldError struct {
+	Key     string // dotted path, e.g. "transport.base_path"
+	Message string // what's wrong with it
+}
+
+func (e *FieldError) Error() string {
+	return e.Key + ": " + e.Message
+}
+
+// validate checks the semantic rules the decoders can't express
+// every problem is reported at once so ops don't play whack-a-mole
+func (c *Config) Validate() error {
+	var errs []error
+	fail := func(key, format string, args ...any) {
+		errs = append(errs, &FieldError{Key: key, Message: fmt.Sprintf(format, args...)})
+	}
+
+	if strings.TrimSpace(c.Server.Name) == "" {
+		fail("server.name", "must not be empty")
+	}
+	if strings.TrimSpace(c.Server.Version) == "" {
+		fail("server.version", "must not be empty")
+	}
+
+	switch c.Transport.Mode {
+	case "stdio":
+	case "sse", "http":
+		if c.Transport.Addr == "" {
+			fail("transport.addr", "is required for the %s transport", c.Transport.Mode)
+		}
+		if !strings.HasPrefix(c.Transport.BasePath, "/") {
+			fail("transport.base_path", "must start with \"/\", got %q", c.Transport.BasePath)
+		}
+	default:
+		fail("transport.mode", "must be one of stdio, sse, http (got %q)", c.Transport.Mode)
+	}
+	if c.Transport.ShutdownTimeout.Duration < 0 {
+		fail("transport.shutdown_timeout", "must not be negative")
+	}
+
+	if c.Log.Path == "" {
+		fail("log.path", "must not be empty (use \"stderr\" to log to standard error)")
+	}
+	if c.Log.Path == "stdout" {
+		fail("log.path", "stdout is reserved for the stdio transport")
+	}
+	if c.Log.Format != "text" && c.Log.Format != "json" {
+		fail("log.format", "must be text or json (got %q)", c.Log.Format)
+	}
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(c.Log.Level)); err != nil {
+		fail("log.level", "must be debug, info, warn or error (got %q)", c.Log.Level)
+	}
+
+	if !strings.Contains(c.Static.URIPrefix, "://") {
+		fail("static.uri_prefix", "must start with a scheme, like \"static://\" (got %q)", c.Static.URIPrefix)
+	}
+
+	if c.Server.ResourceSubscribe {
+		if c.Subscriptions.PollInterval.Duration <= 0 {
+			fail("subscriptions.poll_interval", "must be positive")
+		}
+		if c.Subscriptions.Debounce.Duration < 0 {
+			fail("subscriptions.debounce", "must not be negative")
+		}
+	}
+
+	if c.Middleware.MaxLoggedArgLength < 0 {
+		fail("middleware.max_logged_arg_length", "must not be negative")
+	}
+	if c.Middleware.ToolTimeout.Duration < 0 {
+		fail("middleware.tool_timeout", "must not be negative")
+	}
+	for tool, d := range c.Middleware.ToolTimeouts {
+		if d.Duration < 0 {
+			fail("middleware.tool_timeouts."+tool, "must not be negative")
+		}
+	}
+	switch c.Middleware.OutputValidation {
+	case middleware.OutputValidationOff, middleware.OutputValidationWarn, middleware.OutputValidationStrict:
+	default:
+		fail("middleware.output_validation", "must be off, warn or strict (got %q)", c.Middleware.OutputValidation)
+	}
+	switch c.Middleware.ResourceConsistency {
+	case middleware.ResourceConsistencyOff, middleware.ResourceConsistencyWarn, middleware.ResourceConsistencyStrict:
+	default:
+		fail("middleware.resource_consistency", "must be off, warn or strict (got %q)", c.Middleware.ResourceConsistency)
+	}
+	validateLimits("middleware.default_tool_limits", c.Middleware.DefaultToolLimits, fail)
+	for tool, l := range c.Middleware.ToolLimits {
+		validateLimits("middleware.tool_limits."+tool, l, fail)
+	}
+	for i, pattern := range c.Middleware.RedactArgs {
+		if _, err := path.Match(pattern, ""); err != nil {
+			fail(fmt.Sprintf("middleware.redact_args[%d]", i), "invalid pattern %q: %v", pattern, err)
+		}
+	}
+
+	if c.Metrics.Addr != "" {
+		if !strings.HasPrefix(c.Metrics.Path, "/") {
+			fail("metrics.path", "must start with \"/\", got %q", c.Metrics.Path)
+		}
+		if c.Metrics.Addr == c.Transport.Addr && c.Transport.Mode != "stdio" {
+			fail("metrics.addr", "must differ from transport.addr (%q)", c.Transport.Addr)
+		}
+	}
+
+	if len(c.Metrics.Buckets) > 0 {
+		if err := middleware.ValidateBuckets(c.Metrics.Buckets); err != nil {
+			fail("metrics.buckets", "%v", err)
+		}
+	}
+
+	if _, err := tracing.ParseExporter(c.Tracing.Exporter); err != nil {
+		fail("tracing.exporter", "must be otlp or file, or empty to disable tracing (got %q)", c.Tracing.Exporter)
+	}
+	if c.Tracing.Exporter == string(tracing.ExporterFile) {
+		if c.Tracing.File == "" {
+			fail("tracing.file", "is required for the file exporter")
+		}
+		if c.Tracing.File == "stdout" {
+			fail("tracing.file", "stdout is reserved for the stdio transport")
+		}
+	}
+	if c.Tracing.SampleRatio < 0 || c.Tracing.SampleRatio > 1 {
+		fail("tracing.sample_ratio", "must be between 0 and 1 (got %v)", c.Tracing.SampleRatio)
+	}
+
+	for i, k := range c.Auth.APIKeys {
+		key := fmt.Sprintf("auth.api_keys[%d]", i)
+		if strings.TrimSpace(k.Name) == "" {
+			fail(key+".name", "must not be empty")
+		}
+		switch {
+		case (k.KeySHA256 == "") == (k.KeyEnv == ""):
+			fail(key, "needs exactly one of key_sha256 or key_env")
+		case k.KeySHA256 != "":
+			if _, err := auth.ParseKeyHash(k.KeySHA256); err != nil {
+				fail(key+".key_sha256", "%v", err)
+			}
+		}
+	}
+	if c.Auth.JWT.JWKSFile == "" && (c.Auth.JWT.Issuer != "" || c.Auth.JWT.Audience != "") {
+		fail("auth.jwt.jwks_file", "is required when issuer or audience is set")
+	}
+	if c.Auth.JWT.Leeway.Duration < 0 {
+		fail("auth.jwt.leeway", "must not be negative")
+	}
+
+	validateCapabilities("tools", c.Tools, fail)
+	validateCapabilities("resources", c.Resources, fail)
+	validateCapabilities("prompts", c.Prompts, fail)
+
+	return errors.Join(errs...)
+}
+
+// validateCapabilities catches the contradiction of enabling and disabling the same thing
+func validateCapabilities(section string, c Capabilities, fail func(key, format string, args ...any)) {
+	disabled := make(map[string]bool, len(c.Disabled))
+	for _, d := range c.Disabled {
+		disabled[d] = true
+	}
+	for i, e := range c.Enabled {
+		if e == "" {
+			fail(fmt.Sprintf("%s.enabled[%d]", section, i), "must not be empty")
+		}
+		if disabled[e] {
+			fail(fmt.Sprintf("%s.enabled[%d]", section, i), "%q is also listed in %s.disabled", e, section)
+		}
+	}
+}
+
+// validateLimits rejects negative limits - zero already means "unlimited"
+func validateLimits(key string, l LimitsConfig, fail func(key, format string, args ...any)) {
+	if l.MaxConcurrent < 0 {
+		fail(key+".max_concurrent", "must not be negative")
+	}
+	if l.QueueTimeout.Duration < 0 {
+		fail(key+".queue_timeout", "must not be negative")
+	}
+	if l.Rate < 0 {
+		fail(key+".rate", "must not be negative")
+	}
+	if l.Burst < 0 {
+		fail(key+".burst", "must not be negative")
+	}
+	if l.SessionRate < 0 {
+		fail(key+".session_rate", "must not be negative")
+	}
+	if l.SessionBurst < 0 {
+		fail(key+".session_burst", "must not be negative")
+	}
+}
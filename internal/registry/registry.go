@@ -0,0 +1,104 @@
+package registry
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// entry is one registered capability plus the metadata we keep about it
+type Entry[T any] struct {
+	Name    string   // tool/prompt name or resource URI - must be unique per registry
+	Value   T        // the capability itself
+	Tags    []string // free-form labels for grouping and filtering
+	Enabled bool     // disabled entries stay in the registry but are never served
+}
+
+// option tweaks an entry at registration time
+type Option func(*entryOptions)
+
+type entryOptions struct {
+	tags     []string
+	disabled bool
+}
+
+// withTags labels an entry, e.g. WithTags("demo", "text")
+func WithTags(tags ...string) Option {
+	return func(o *entryOptions) {
+		o.tags = append(o.tags, tags...)
+	}
+}
+
+// disabled registers an entry that is switched off until config turns it on
+// useful for experimental capabilities that ship dark
+func Disabled() Option {
+	return func(o *entryOptions) {
+		o.disabled = true
+	}
+}
+
+// registry collects capabilities of one kind as packages register themselves
+// registration usually happens in init(), so mistakes are recorded and
+// reported later by Entries instead of panicking before logging is set up
+type Registry[T any] struct {
+	kind string // "tool", "resource" or "prompt" - only used in error messages
+
+	mu      sync.Mutex
+	entries map[string]Entry[T]
+	errs    []error
+}
+
+// new creates an empty registry for the given kind of capability
+func New[T any](kind string) *Registry[T] {
+	return &Registry[T]{
+		kind:    kind,
+		entries: make(map[string]Entry[T]),
+	}
+}
+
+// add registers a capability under name
+// a second registration with the same name is an error, never a silent overwrite
+func (r *Registry[T]) Add(name string, value T, opts ...Option) {
+	var o entryOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if name == "" {
+		r.errs = append(r.errs, fmt.Errorf("%s registered with an empty name", r.kind))
+		return
+	}
+	if _, exists := r.entries[name]; exists {
+		r.errs = append(r.errs, fmt.Errorf("duplicate %s %q", r.kind, name))
+		return
+	}
+	r.entries[name] = Entry[T]{
+		Name:    name,
+		Value:   value,
+		Tags:    o.tags,
+		Enabled: !o.disabled,
+	}
+}
+
+// entries returns every registration sorted by name, or the collected
+// registration errors if anything went wrong
+func (r *Registry[T]) Entries() ([]Entry[T], error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if len(r.errs) > 0 {
+		return nil, errors.Join(r.errs...)
+	}
+
+	out := make([]Entry[T], 0, len(r.entries))
+	for _, e := range r.entries {
+		out = append(out, e)
+	}
+	// map iteration order is random - sort so tools/list is stable between runs
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out, nil
+}
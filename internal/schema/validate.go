@@ -0,0 +1,278 @@
+package schema
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+// problem is one way a value fails its schema
+type Problem struct {
+	Path    string `json:"path"`    // where in the value, e.g. "name" or "items[2].id"; empty means the value itself
+	Message string `json:"message"` // what's wrong with it
+}
+
+func (p Problem) String() string {
+	if p.Path == "" {
+		return p.Message
+	}
+	return p.Path + ": " + p.Message
+}
+
+// fromJSON turns anything that marshals to a JSON schema (mcp.ToolInputSchema, json.RawMessage,
+// a plain map) into the generic map the validator walks
+func FromJSON(v any) (map[string]any, error) {
+	var b []byte
+	switch s := v.(type) {
+	case json.RawMessage:
+		b = s
+	case []byte:
+		b = s
+	default:
+		var err error
+		if b, err = json.Marshal(v); err != nil {
+			return nil, err
+		}
+	}
+	var out map[string]any
+	if err := json.Unmarshal(b, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// validate checks a decoded JSON value against a schema and reports every problem at once
+// it understands the subset of JSON Schema that tool definitions actually use: type, required,
+// properties, additionalProperties, items, enum, const, minimum/maximum (and exclusive variants),
+// minLength/maxLength, pattern and minItems/maxItems - anything else is ignored, never rejected
+func Validate(schema map[string]any, value any) []Problem {
+	var problems []Problem
+	validate(schema, value, "", &problems)
+	return problems
+}
+
+func validate(schema map[string]any, value any, path string, problems *[]Problem) {
+	fail := func(format string, args ...any) {
+		*problems = append(*problems, Problem{Path: path, Message: fmt.Sprintf(format, args...)})
+	}
+
+	if t, ok := schema["type"]; ok && !matchesType(t, value) {
+		fail("must be %s, got %s", describeType(t), typeOf(value))
+		return // the remaining keywords assume the right type
+	}
+
+	if enum, ok := schema["enum"].([]any); ok && !slices.ContainsFunc(enum, func(e any) bool { return equal(e, value) }) {
+		fail("must be one of %s", formatValues(enum))
+	}
+	if c, ok := schema["const"]; ok && !equal(c, value) {
+		fail("must be %s", formatValues([]any{c}))
+	}
+
+	switch v := value.(type) {
+	case string:
+		n := utf8.RuneCountInString(v)
+		if min, ok := number(schema["minLength"]); ok && float64(n) < min {
+			fail("must be at least %v characters long", min)
+		}
+		if max, ok := number(schema["maxLength"]); ok && float64(n) > max {
+			fail("must be at most %v characters long", max)
+		}
+		if pattern, ok := schema["pattern"].(string); ok {
+			if re, err := compile(pattern); err != nil {
+				fail("schema pattern %q is invalid: %v", pattern, err)
+			} else if !re.MatchString(v) {
+				fail("must match pattern %q", pattern)
+			}
+		}
+
+	case float64:
+		if min, ok := number(schema["minimum"]); ok && v < min {
+			fail("must be >= %v", min)
+		}
+		if max, ok := number(schema["maximum"]); ok && v > max {
+			fail("must be <= %v", max)
+		}
+		if min, ok := number(schema["exclusiveMinimum"]); ok && v <= min {
+			fail("must be > %v", min)
+		}
+		if max, ok := number(schema["exclusiveMaximum"]); ok && v >= max {
+			fail("must be < %v", max)
+		}
+
+	case []any:
+		if min, ok := number(schema["minItems"]); ok && float64(len(v)) < min {
+			fail("must have at least %v items", min)
+		}
+		if max, ok := number(schema["maxItems"]); ok && float64(len(v)) > max {
+			fail("must have at most %v items", max)
+		}
+		if items, ok := schema["items"].(map[string]any); ok {
+			for i, item := range v {
+				validate(items, item, fmt.Sprintf("%s[%d]", path, i), problems)
+			}
+		}
+
+	case map[string]any:
+		if required, ok := schema["required"].([]any); ok {
+			for _, r := range required {
+				if name, ok := r.(string); ok {
+					if _, present := v[name]; !present {
+						*problems = append(*problems, Problem{Path: join(path, name), Message: "is required"})
+					}
+				}
+			}
+		}
+		properties, _ := schema["properties"].(map[string]any)
+		for _, name := range sortedKeys(v) {
+			if prop, ok := properties[name].(map[string]any); ok {
+				validate(prop, v[name], join(path, name), problems)
+				continue
+			}
+			switch extra := schema["additionalProperties"].(type) {
+			case bool:
+				if !extra {
+					*problems = append(*problems, Problem{Path: join(path, name), Message: "is not a known property"})
+				}
+			case map[string]any:
+				validate(extra, v[name], join(path, name), problems)
+			}
+		}
+	}
+}
+
+// matchesType checks the "type" keyword, which may be a single name or a list of them
+func matchesType(t any, value any) bool {
+	switch t := t.(type) {
+	case string:
+		return isType(t, value)
+	case []any:
+		return slices.ContainsFunc(t, func(name any) bool {
+			s, _ := name.(string)
+			return isType(s, value)
+		})
+	}
+	return true // a type keyword we don't understand shouldn't reject anything
+}
+
+func isType(name string, value any) bool {
+	switch name {
+	case "string":
+		_, ok := value.(string)
+		return ok
+	case "number":
+		_, ok := value.(float64)
+		return ok
+	case "integer":
+		f, ok := value.(float64)
+		return ok && f == math.Trunc(f)
+	case "boolean":
+		_, ok := value.(bool)
+		return ok
+	case "object":
+		_, ok := value.(map[string]any)
+		return ok
+	case "array":
+		_, ok := value.([]any)
+		return ok
+	case "null":
+		return value == nil
+	}
+	return true
+}
+
+// typeOf names a decoded JSON value's type the way a schema would
+func typeOf(value any) string {
+	switch v := value.(type) {
+	case nil:
+		return "null"
+	case string:
+		return "string"
+	case float64:
+		if v == math.Trunc(v) {
+			return "integer"
+		}
+		return "number"
+	case bool:
+		return "boolean"
+	case map[string]any:
+		return "object"
+	case []any:
+		return "array"
+	}
+	return fmt.Sprintf("%T", value)
+}
+
+func describeType(t any) string {
+	if list, ok := t.([]any); ok {
+		names := make([]string, len(list))
+		for i, n := range list {
+			names[i] = fmt.Sprint(n)
+		}
+		return "one of " + strings.Join(names, ", ")
+	}
+	return fmt.Sprintf("%s", t)
+}
+
+// number reads a numeric schema keyword; JSON decoding gives us float64
+func number(v any) (float64, bool) {
+	switch n := v.(type) {
+	case float64:
+		return n, true
+	case int:
+		return float64(n), true
+	}
+	return 0, false
+}
+
+// equal compares decoded JSON values, which is what enum and const need
+func equal(a, b any) bool {
+	ja, errA := json.Marshal(a)
+	jb, errB := json.Marshal(b)
+	return errA == nil && errB == nil && string(ja) == string(jb)
+}
+
+func formatValues(values []any) string {
+	parts := make([]string, len(values))
+	for i, v := range values {
+		b, _ := json.Marshal(v)
+		parts[i] = string(b)
+	}
+	return strings.Join(parts, ", ")
+}
+
+func join(path, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
+
+func sortedKeys(m map[string]any) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// patterns caches compiled regular expressions - schemas are fixed, calls are not
+var patterns sync.Map
+
+func compile(pattern string) (*regexp.Regexp, error) {
+	if re, ok := patterns.Load(pattern); ok {
+		return re.(*regexp.Regexp), nil
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, err
+	}
+	patterns.Store(pattern, re)
+	return re, nil
+}
@@ -0,0 +1,132 @@
+package schema
+
+import (
+	"encoding/json"
+	"slices"
+	"testing"
+)
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name   string
+		schema string
+		value  string
+		want   []string // Problem.String() of every problem, in order; empty means valid
+	}{
+		// type
+		{"string ok", `{"type":"string"}`, `"hi"`, nil},
+		{"string wrong", `{"type":"string"}`, `3`, []string{"must be string, got integer"}},
+		{"number takes fractions", `{"type":"number"}`, `1.5`, nil},
+		{"number takes integers", `{"type":"number"}`, `2`, nil},
+		{"integer ok", `{"type":"integer"}`, `2`, nil},
+		{"integer takes 2.0", `{"type":"integer"}`, `2.0`, nil},
+		{"integer rejects fractions", `{"type":"integer"}`, `2.5`, []string{"must be integer, got number"}},
+		{"boolean wrong", `{"type":"boolean"}`, `"true"`, []string{"must be boolean, got string"}},
+		{"null ok", `{"type":"null"}`, `null`, nil},
+		{"object wrong", `{"type":"object"}`, `[]`, []string{"must be object, got array"}},
+		{"array wrong", `{"type":"array"}`, `{}`, []string{"must be array, got object"}},
+		{"type list ok", `{"type":["string","null"]}`, `null`, nil},
+		{"type list wrong", `{"type":["string","null"]}`, `true`, []string{"must be one of string, null, got boolean"}},
+		{"unknown type ignored", `{"type":"widget"}`, `1`, nil},
+		{"wrong type skips other keywords", `{"type":"string","minLength":5}`, `1`, []string{"must be string, got integer"}},
+
+		// enum and const
+		{"enum ok", `{"enum":["a","b"]}`, `"b"`, nil},
+		{"enum wrong", `{"enum":["a","b"]}`, `"c"`, []string{`must be one of "a", "b"`}},
+		{"enum compares objects", `{"enum":[{"x":1}]}`, `{"x":1}`, nil},
+		{"const ok", `{"const":3}`, `3`, nil},
+		{"const wrong", `{"const":3}`, `4`, []string{"must be 3"}},
+
+		// numbers
+		{"minimum ok at bound", `{"minimum":1}`, `1`, nil},
+		{"minimum wrong", `{"minimum":1}`, `0`, []string{"must be >= 1"}},
+		{"maximum ok at bound", `{"maximum":10}`, `10`, nil},
+		{"maximum wrong", `{"maximum":10}`, `11`, []string{"must be <= 10"}},
+		{"exclusiveMinimum rejects bound", `{"exclusiveMinimum":1}`, `1`, []string{"must be > 1"}},
+		{"exclusiveMinimum ok", `{"exclusiveMinimum":1}`, `1.5`, nil},
+		{"exclusiveMaximum rejects bound", `{"exclusiveMaximum":10}`, `10`, []string{"must be < 10"}},
+		{"exclusiveMaximum ok", `{"exclusiveMaximum":10}`, `9.9`, nil},
+
+		// strings
+		{"minLength counts runes", `{"minLength":2}`, `"éé"`, nil},
+		{"minLength wrong", `{"minLength":2}`, `"a"`, []string{"must be at least 2 characters long"}},
+		{"maxLength wrong", `{"maxLength":2}`, `"abc"`, []string{"must be at most 2 characters long"}},
+		{"pattern ok", `{"pattern":"^[a-z]+$"}`, `"abc"`, nil},
+		{"pattern wrong", `{"pattern":"^[a-z]+$"}`, `"aBc"`, []string{`must match pattern "^[a-z]+$"`}},
+		{"pattern is a search", `{"pattern":"b"}`, `"abc"`, nil},
+		{"invalid pattern reported", `{"pattern":"("}`, `"x"`, []string{"schema pattern \"(\" is invalid: error parsing regexp: missing closing ): `(`"}},
+
+		// arrays
+		{"minItems wrong", `{"minItems":2}`, `[1]`, []string{"must have at least 2 items"}},
+		{"maxItems wrong", `{"maxItems":1}`, `[1,2]`, []string{"must have at most 1 items"}},
+		{"items ok", `{"items":{"type":"integer"}}`, `[1,2]`, nil},
+		{"items wrong", `{"items":{"type":"integer"}}`, `[1,"x",3.5]`, []string{"[1]: must be integer, got string", "[2]: must be integer, got number"}},
+
+		// objects
+		{"required ok", `{"required":["a"]}`, `{"a":1}`, nil},
+		{"required missing", `{"required":["a","b"]}`, `{}`, []string{"a: is required", "b: is required"}},
+		{"properties checked", `{"properties":{"a":{"type":"string"}}}`, `{"a":1}`, []string{"a: must be string, got integer"}},
+		{"additionalProperties allowed by default", `{"properties":{"a":{}}}`, `{"b":1}`, nil},
+		{"additionalProperties false", `{"properties":{"a":{}},"additionalProperties":false}`, `{"a":1,"b":2}`, []string{"b: is not a known property"}},
+		{"additionalProperties schema", `{"additionalProperties":{"type":"string"}}`, `{"x":"ok","y":2}`, []string{"y: must be string, got integer"}},
+		{"every problem reported, keys sorted", `{"properties":{"a":{"type":"string"},"b":{"type":"string"}},"required":["c"]}`, `{"b":1,"a":2}`,
+			[]string{"c: is required", "a: must be string, got integer", "b: must be string, got integer"}},
+
+		// nested paths
+		{"nested object path", `{"properties":{"user":{"properties":{"name":{"type":"string"}},"required":["id"]}}}`, `{"user":{"name":5}}`,
+			[]string{"user.id: is required", "user.name: must be string, got integer"}},
+		{"nested array path", `{"properties":{"items":{"items":{"properties":{"id":{"type":"integer"}}}}}}`, `{"items":[{"id":1},{"id":"two"}]}`,
+			[]string{"items[1].id: must be integer, got string"}},
+		{"array of arrays path", `{"items":{"items":{"type":"boolean"}}}`, `[[true],[false,0]]`, []string{"[1][1]: must be boolean, got integer"}},
+
+		// keywords we don't know are ignored
+		{"unknown keyword ignored", `{"format":"email","oneOf":[{"type":"integer"}]}`, `"not an email"`, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := FromJSON(json.RawMessage(tt.schema))
+			if err != nil {
+				t.Fatalf("schema: %v", err)
+			}
+			var value any
+			if err := json.Unmarshal([]byte(tt.value), &value); err != nil {
+				t.Fatalf("value: %v", err)
+			}
+
+			var got []string
+			for _, p := range Validate(s, value) {
+				got = append(got, p.String())
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("Validate(%s, %s)\n got  %q\n want %q", tt.schema, tt.value, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFromJSON(t *testing.T) {
+	type schemaStruct struct {
+		Type     string   `json:"type"`
+		Required []string `json:"required"`
+	}
+	for _, src := range []any{
+		json.RawMessage(`{"type":"object","required":["a"]}`),
+		[]byte(`{"type":"object","required":["a"]}`),
+		map[string]any{"type": "object", "required": []string{"a"}},
+		schemaStruct{Type: "object", Required: []string{"a"}},
+	} {
+		s, err := FromJSON(src)
+		if err != nil {
+			t.Fatalf("FromJSON(%T): %v", src, err)
+		}
+		// every source must come out in the shape Validate walks
+		if got := Validate(s, map[string]any{}); len(got) != 1 || got[0].String() != "a: is required" {
+			t.Errorf("FromJSON(%T): Validate = %v, want a: is required", src, got)
+		}
+	}
+
+	if _, err := FromJSON(json.RawMessage(`[1,2]`)); err == nil {
+		t.Error("FromJSON of a non-object: want an error")
+	}
+}
@@ -1,65 +1,419 @@
 package main
 
 import (
+	"context"
+	"embed"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/fs"
 	"log"
+	"log/slog"
+	"net/http"
 	"os"
+	"os/signal"
+	"strings"
+	"syscall"
 
+	"github.com/mark3labs/mcp-go/mcp"
 	server "github.com/mark3labs/mcp-go/server"
+	"github.com/suramrit/hello-mcp/auth"
+	"github.com/suramrit/hello-mcp/config"
+	"github.com/suramrit/hello-mcp/internal/registry"
 	"github.com/suramrit/hello-mcp/middleware"
 	"github.com/suramrit/hello-mcp/prompts"
 	"github.com/suramrit/hello-mcp/resources"
+	"github.com/suramrit/hello-mcp/status"
+	"github.com/suramrit/hello-mcp/subscriptions"
 	"github.com/suramrit/hello-mcp/tools"
+	"github.com/suramrit/hello-mcp/tracing"
+	"github.com/suramrit/hello-mcp/transport"
 )
 
+// projectDocs is our own README, compiled in so the README resource works from any working directory
+//
+//go:embed README.md
+var projectDocs embed.FS
+
 // main is where the magic begins!
 // this is the entry point for our MCP server that will give AI superpowers
 func main() {
+	// the config file is optional - without one we behave exactly like we always have
+	configPath := flag.String("config", os.Getenv(config.EnvPrefix+"CONFIG"), "path to a .yaml, .json or .toml config file")
+	// pick a transport - stdio by default so desktop clients keep working unchanged
+	transportFlag := flag.String("transport", "", "transport to serve on: stdio, sse or http (overrides config)")
+	addr := flag.String("addr", "", "listen address for the sse and http transports (overrides config)")
+	basePath := flag.String("base-path", "", "URL path prefix for the sse and http transports (overrides config)")
+	shutdownTimeout := flag.Duration("shutdown-timeout", 0, "grace period for in-flight requests on shutdown (overrides config)")
+	flag.Parse()
+
+	cfg, err := config.Load(*configPath)
+	if err != nil {
+		log.Fatalf("invalid configuration: %v", err) // tell the human before we hide in the log file
+	}
+
+	// flags beat the config file, but only the ones actually passed on the command line
+	flag.Visit(func(f *flag.Flag) {
+		switch f.Name {
+		case "transport":
+			cfg.Transport.Mode = *transportFlag
+		case "addr":
+			cfg.Transport.Addr = *addr
+		case "base-path":
+			cfg.Transport.BasePath = *basePath
+		case "shutdown-timeout":
+			cfg.Transport.ShutdownTimeout.Duration = *shutdownTimeout
+		}
+	})
+	if err := cfg.Validate(); err != nil {
+		log.Fatalf("invalid configuration: %v", err)
+	}
+
+	mode, err := transport.ParseMode(cfg.Transport.Mode)
+	if err != nil {
+		log.Fatal(err)
+	}
+
 	// create a log file because stdout/stderr get hijacked by MCP protocol
 	// think of this as our server's diary - it'll tell us everything that happens!
-	logFile, err := os.OpenFile("mcp-server.log", os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0666)
+	logOutput, err := openLog(cfg.Log.Path)
 	if err != nil {
 		log.Fatal(err) // if we can't log, we can't debug - bail out!
 	}
-	defer logFile.Close() // always clean up after yourself, mom would be proud
+	defer logOutput.Close() // always clean up after yourself, mom would be proud
 
-	// redirect all our chatty log messages to the file
-	log.SetOutput(logFile)
+	// redirect all our chatty log messages to the file, as structured records our pipeline can parse
+	// slog.SetDefault also routes the plain log package through the same handler
+	logHandler, err := middleware.NewLogHandler(logOutput, cfg.Log.Format, cfg.Log.Level)
+	if err != nil {
+		log.Fatal(err)
+	}
+	slog.SetDefault(slog.New(logHandler))
 	log.Println("=== MCP Server Starting ===") // and... action!
+	if *configPath != "" {
+		log.Printf("Loaded configuration from %s", *configPath)
+	}
+
+	// the middleware stack follows the config too - opting out is allowed, just not the default
+	middleware.GlobalSettings = middleware.Settings{
+		Logging:             cfg.Middleware.Logging,
+		Recovery:            cfg.Middleware.Recovery,
+		Metrics:             cfg.Middleware.Metrics,
+		Tracing:             cfg.Tracing.Exporter != "",
+		Validation:          cfg.Middleware.Validation,
+		DefaultToolTimeout:  cfg.Middleware.ToolTimeout.Duration,
+		CrashDir:            cfg.Middleware.CrashDir,
+		OutputValidation:    cfg.Middleware.OutputValidation,
+		ResourceConsistency: cfg.Middleware.ResourceConsistency,
+
+		AllowDestructiveTools: cfg.Middleware.AllowDestructiveTools,
+	}
+	middleware.GlobalRedaction.Patterns = append(middleware.GlobalRedaction.Patterns, cfg.Middleware.RedactArgs...)
+	middleware.GlobalRedaction.MaxValueLength = cfg.Middleware.MaxLoggedArgLength
+	if len(cfg.Metrics.Buckets) > 0 {
+		middleware.GlobalMetrics.LatencyBuckets = cfg.Metrics.Buckets
+	}
+
+	// spans go wherever the config says - or nowhere, and the tracing layer stays out of the chain
+	shutdownTracing, err := tracing.Setup(context.Background(), tracing.Options{
+		Exporter:       tracing.Exporter(cfg.Tracing.Exporter),
+		Endpoint:       cfg.Tracing.Endpoint,
+		Insecure:       cfg.Tracing.Insecure,
+		File:           cfg.Tracing.File,
+		SampleRatio:    cfg.Tracing.SampleRatio,
+		ServiceName:    cfg.Server.Name,
+		ServiceVersion: cfg.Server.Version,
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer func() {
+		// flush the last spans; don't let a dead collector hold up the exit forever
+		ctx, cancel := context.WithTimeout(context.Background(), cfg.Transport.ShutdownTimeout.Duration)
+		defer cancel()
+		if err := shutdownTracing(ctx); err != nil {
+			log.Printf("Tracing shutdown: %v", err)
+		}
+	}()
+
+	// one set of hooks for everything below; the SDK keeps only the last WithHooks it's given
+	hooks := &server.Hooks{}
+	srvOpts := append(serverOptions(cfg.Server), server.WithHooks(hooks))
+
+	// resource reads and prompt gets join the client's trace too, not just tool calls
+	if middleware.GlobalSettings.Tracing {
+		middleware.AddTraceHooks(hooks)
+	}
+
+	// who may use what - without a policy file every caller sees and uses everything
+	if cfg.Auth.PolicyFile != "" {
+		policy, err := auth.LoadPolicy(cfg.Auth.PolicyFile)
+		if err != nil {
+			log.Fatal(err)
+		}
+		middleware.SetPolicy(policy)
+		// hide what a caller can't use, rather than showing it and refusing later
+		srvOpts = append(srvOpts, server.WithToolFilter(middleware.FilterTools))
+		middleware.AddListFilters(hooks)
+		log.Printf("Authorization policy loaded from %s", cfg.Auth.PolicyFile)
+	}
 
 	// build our MCP server - this is the foundation everything sits on
 	srv := server.NewMCPServer(
-		"hello-mcp",                        // server name - keep it friendly!
-		"0.1.0",                            // version - we're just getting started
-		server.WithToolCapabilities(false), // we'll handle tool capabilities ourselves
-		server.WithLogging(),               // enable the SDK's internal logging too
+		cfg.Server.Name,    // server name - keep it friendly!
+		cfg.Server.Version, // version - we're just getting started
+		srvOpts...,
 	)
 
+	status.SetServerInfo(cfg.Server.Name, cfg.Server.Version) // so server_stats knows who we are
+
+	// subscriptions tell clients when a file-backed resource changes
+	var subs *subscriptions.Manager
+	if cfg.Server.ResourceSubscribe {
+		subs = subscriptions.New(srv, subscriptions.Options{
+			PollInterval: cfg.Subscriptions.PollInterval.Duration,
+			Debounce:     cfg.Subscriptions.Debounce.Duration,
+			Authorize: func(ctx context.Context, uri string) bool {
+				return middleware.Authorized(ctx, "resource", uri)
+			},
+		})
+		subs.AddHooks(hooks)
+	}
+
 	// time to set up the three-ring circus of MCP capabilities!
 	log.Println("Registering tools, resources, and prompts...")
 
+	// everything below comes from the registries - capabilities sign themselves up in init()
+	// a duplicate name anywhere is fatal: better a loud startup than a silently shadowed tool
+
 	// tools: let AI DO things (like our friendly echo)
-	registerTools(srv, tools.NewEchoTool())
+	if err := registerTools(srv, cfg); err != nil {
+		log.Fatal(err)
+	}
+
+	// static files are compiled in, so they're found whatever directory we were started from
+	// an override directory, if there is one, beats the bundled copies file by file
+	var override fs.FS
+	if cfg.Static.Dir != "" {
+		root, err := os.OpenRoot(cfg.Static.Dir) // a root, so symlinks can't reach outside it
+		switch {
+		case errors.Is(err, fs.ErrNotExist):
+			log.Printf("Static override directory %s not found, serving the bundled files only", cfg.Static.Dir)
+		case err != nil:
+			log.Fatal(err)
+		default:
+			override = root.FS()
+			log.Printf("Static files in %s override the bundled ones", cfg.Static.Dir)
+		}
+	}
+	staticFiles := resources.Overlay(override, resources.Bundled())
+	resources.SetReadmeFiles(projectDocs)
+
+	// static files sign up here rather than in init() - what to scan is config's call
+	n, err := resources.NewStaticDirResource(staticFiles, cfg.Static.URIPrefix).Register()
+	if err != nil {
+		log.Fatal(err)
+	}
+	log.Printf("Found %d static resources", n)
+
+	// the file template is off unless config names a root - it hands out whatever is inside
+	if cfg.Files.Root != "" {
+		files, err := resources.NewFileTemplate(cfg.Files.Root)
+		if err != nil {
+			log.Fatal(err)
+		}
+		resources.RegisterTemplate(files, registry.WithTags("files"))
+		log.Printf("Serving files below %s as file:///{+path}", cfg.Files.Root)
+	}
 
 	// resources: give AI access to data (like our README file)
-	registerResources(srv, resources.NewReadmeResource())
+	if err := registerResources(srv, cfg, subs); err != nil {
+		log.Fatal(err)
+	}
+
+	// read every resource once now, so a mislabelled one or a missing file shows up in the log
+	// before a client trips over it; strict refuses to start at all
+	if err := checkResources(cfg); err != nil {
+		log.Fatal(err)
+	}
 
 	// prompts: provide AI with conversation templates
-	registerPrompts(srv, prompts.NewGreetingPrompt())
+	if err := registerPrompts(srv, cfg); err != nil {
+		log.Fatal(err)
+	}
+
+	// stop cleanly on ctrl-c or when the orchestrator asks us to leave
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	if subs != nil {
+		go subs.Run(ctx)
+	}
+
+	// the metrics exporter lives on its own port so it works next to any transport, stdio included
+	if cfg.Metrics.Addr != "" {
+		mux := http.NewServeMux()
+		mux.Handle(cfg.Metrics.Path, middleware.MetricsHandler())
+		go func() {
+			log.Printf("Metrics exporter listening on %s%s", cfg.Metrics.Addr, cfg.Metrics.Path)
+			if err := transport.ServeHandler(ctx, cfg.Metrics.Addr, mux, cfg.Transport.ShutdownTimeout.Duration); err != nil {
+				log.Printf("Metrics exporter stopped: %v", err) // losing metrics shouldn't take the server down
+			}
+		}()
+	}
 
-	log.Println("Starting stdio server...")
-	// launch! This blocks forever, listening for JSON-RPC over stdin/stdout
-	// the MCP client (like Claude Desktop) will spawn us and talk to us here
-	if err := server.ServeStdio(srv); err != nil {
+	// over the network, callers have to say who they are - stdio is trusted by whoever spawned us
+	opts := transport.Options{
+		Mode:            mode,
+		Addr:            cfg.Transport.Addr,
+		BasePath:        cfg.Transport.BasePath,
+		ShutdownTimeout: cfg.Transport.ShutdownTimeout.Duration,
+	}
+	if subs != nil {
+		opts.Rewrite = subscriptions.Rewrite // the SDK doesn't know resources/subscribe - see there
+		opts.OnSessionEnd = subs.EndSession
+	}
+	switch {
+	case mode == transport.ModeStdio:
+		if cfg.Auth.Enabled() {
+			log.Println("Auth is configured but ignored on the stdio transport")
+		}
+	case cfg.Auth.Enabled():
+		authenticator, err := newAuthenticator(cfg.Auth)
+		if err != nil {
+			log.Fatal(err)
+		}
+		opts.Auth = authenticator.Middleware
+	default:
+		log.Printf("WARNING: no auth configured - anyone who can reach %s can call every tool", cfg.Transport.Addr)
+	}
+
+	log.Printf("Starting %s server...", mode)
+	// launch! This blocks until we're told to stop (or the client hangs up on stdio)
+	if err := transport.Serve(ctx, srv, opts); err != nil {
 		log.Fatal(err) // if the server dies, we die with it
 	}
+	log.Println("=== MCP Server Stopped ===")
+}
+
+// openLog opens the log destination named in the config
+// "stderr" is allowed for containers; stdout never is - that's the protocol's turf
+func openLog(path string) (io.WriteCloser, error) {
+	if path == "stderr" {
+		return nopCloser{os.Stderr}, nil
+	}
+	return os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0666)
+}
+
+// nopCloser keeps us from closing stderr out from under the runtime
+type nopCloser struct{ io.Writer }
+
+func (nopCloser) Close() error { return nil }
+
+// newAuthenticator turns the auth section of the config into an auth.Authenticator
+func newAuthenticator(cfg config.AuthConfig) (*auth.Authenticator, error) {
+	opts := auth.Options{
+		JWKSFile:   cfg.JWT.JWKSFile,
+		Issuer:     cfg.JWT.Issuer,
+		Audience:   cfg.JWT.Audience,
+		RolesClaim: cfg.JWT.RolesClaim,
+		Leeway:     cfg.JWT.Leeway.Duration,
+	}
+	for _, k := range cfg.APIKeys {
+		key := auth.APIKey{Name: k.Name, Roles: k.Roles}
+		if k.KeyEnv != "" {
+			secret := os.Getenv(k.KeyEnv)
+			if secret == "" {
+				return nil, fmt.Errorf("API key %s: environment variable %s is empty", k.Name, k.KeyEnv)
+			}
+			key.SHA256 = auth.HashKey(secret)
+		} else {
+			sum, err := auth.ParseKeyHash(k.KeySHA256)
+			if err != nil {
+				return nil, fmt.Errorf("API key %s: %w", k.Name, err)
+			}
+			key.SHA256 = sum
+		}
+		opts.APIKeys = append(opts.APIKeys, key)
+	}
+	return auth.New(opts)
+}
+
+// serverOptions turns the server section of the config into SDK options
+func serverOptions(cfg config.ServerConfig) []server.ServerOption {
+	opts := []server.ServerOption{
+		server.WithToolCapabilities(cfg.ToolListChanged),
+		server.WithResourceCapabilities(cfg.ResourceSubscribe, cfg.ResourceListChanged),
+		server.WithPromptCapabilities(cfg.PromptListChanged),
+	}
+	if cfg.Logging {
+		opts = append(opts, server.WithLogging()) // enable the SDK's internal logging too
+	}
+	if cfg.Instructions != "" {
+		opts = append(opts, server.WithInstructions(cfg.Instructions))
+	}
+	return opts
 }
 
 // registerTools wraps our tools with middleware and registers them
 // think of this as putting on safety gear before using power tools!
-func registerTools(srv *server.MCPServer, tools ...tools.Tool) {
-	for _, tool := range tools {
-		toolDef := tool.GetTool()    // get the tool's definition (name, params, etc.)
-		handler := tool.GetHandler() // get the actual function that does the work
+func registerTools(srv *server.MCPServer, cfg *config.Config) error {
+	entries, err := tools.Registered()
+	if err != nil {
+		return fmt.Errorf("tool registry: %w", err)
+	}
+
+	for _, entry := range entries {
+		if !cfg.Tools.Allows(entry.Name, entry.Tags, entry.Enabled) {
+			log.Printf("Skipping disabled tool: %s", entry.Name)
+			continue
+		}
+		toolDef := tools.Definition(entry.Value) // get the tool's definition (name, params, hints, etc.)
+		handler := entry.Value.GetHandler()      // get the actual function that does the work
+
+		// secrets stay out of the log file - tools tell us which arguments are private
+		if s, ok := entry.Value.(tools.SensitiveTool); ok {
+			middleware.DeclareSensitiveToolArgs(toolDef.Name, s.SensitiveArguments()...)
+		}
+
+		// arguments get checked against the declared schema before the handler sees them,
+		// and in debug mode results get checked against theirs on the way out
+		if err := middleware.SetToolInputSchema(toolDef); err != nil {
+			return err
+		}
+		if err := middleware.SetToolOutputSchema(toolDef); err != nil {
+			return err
+		}
+
+		// clients see the behaviour hints in tools/list; we use them to keep destructive tools locked up
+		middleware.SetToolAnnotations(toolDef.Name, toolDef.Annotations)
+		if middleware.IsDestructive(toolDef.Annotations) && !cfg.Middleware.AllowDestructiveTools {
+			log.Printf("Tool %s is destructive; calls will be refused (set middleware.allow_destructive_tools to allow them)", toolDef.Name)
+		}
+
+		// no tool gets to hang forever - config beats the tool's own guess, which beats the global default
+		if d, ok := cfg.Middleware.ToolTimeouts[toolDef.Name]; ok {
+			middleware.SetToolTimeout(toolDef.Name, d.Duration)
+		} else if t, ok := entry.Value.(tools.TimeoutTool); ok {
+			middleware.SetToolTimeout(toolDef.Name, t.Timeout())
+		}
+
+		// expensive tools get a bouncer - per-tool config wins over the default
+		limits, ok := cfg.Middleware.ToolLimits[toolDef.Name]
+		if !ok {
+			limits = cfg.Middleware.DefaultToolLimits
+		}
+		if limits != (config.LimitsConfig{}) {
+			middleware.SetToolLimits(toolDef.Name, middleware.ToolLimits{
+				MaxConcurrent: limits.MaxConcurrent,
+				QueueTimeout:  limits.QueueTimeout.Duration,
+				Rate:          limits.Rate,
+				Burst:         limits.Burst,
+				SessionRate:   limits.SessionRate,
+				SessionBurst:  limits.SessionBurst,
+			})
+		}
 
 		// wrap with middleware: logging, metrics, panic recovery
 		// it's like having a safety net for our trapeze artists!
@@ -67,35 +421,135 @@ func registerTools(srv *server.MCPServer, tools ...tools.Tool) {
 
 		// register with the server - now AI can call this tool!
 		srv.AddTool(toolDef, wrappedHandler)
+		status.RecordTool(toolDef.Name)
 	}
+	return nil
 }
 
 // registerResources gives AI access to data sources
 // like giving AI a library card!
-func registerResources(srv *server.MCPServer, resources ...resources.Resource) {
-	for _, resource := range resources {
-		resourceDef := resource.GetResource() // what is this resource?
-		handler := resource.GetHandler()      // how do we read it?
+// subs, when not nil, learns which resources are file-backed and so can be subscribed to
+func registerResources(srv *server.MCPServer, cfg *config.Config, subs *subscriptions.Manager) error {
+	entries, err := resources.Registered()
+	if err != nil {
+		return fmt.Errorf("resource registry: %w", err)
+	}
+
+	for _, entry := range entries {
+		if !cfg.Resources.Allows(entry.Name, entry.Tags, entry.Enabled) {
+			log.Printf("Skipping disabled resource: %s", entry.Name)
+			continue
+		}
+		resourceDef := entry.Value.GetResource() // what is this resource?
+		handler := entry.Value.GetHandler()      // how do we read it?
 
 		// same middleware magic - safety first!
+		middleware.DeclareResourceMIMEType(resourceDef.URI, resourceDef.MIMEType)
 		wrappedHandler := middleware.WithResourceMiddleware(resourceDef.URI, handler)
 
 		// now AI can ask for this data whenever it needs it
 		srv.AddResource(resourceDef, wrappedHandler)
+		status.RecordResource(resourceDef.URI)
+		if st, ok := entry.Value.(subscriptions.Stater); ok && subs != nil {
+			subs.Watch(resourceDef.URI, st)
+		}
 	}
+
+	// templates answer for whole families of URIs; they're filtered by their URI template
+	templates, err := resources.RegisteredTemplates()
+	if err != nil {
+		return fmt.Errorf("resource template registry: %w", err)
+	}
+	for _, entry := range templates {
+		if !cfg.Resources.Allows(entry.Name, entry.Tags, entry.Enabled) {
+			log.Printf("Skipping disabled resource template: %s", entry.Name)
+			continue
+		}
+		templateDef := entry.Value.GetTemplate()
+		middleware.DeclareResourceMIMEType(entry.Name, templateDef.MIMEType)
+		handler := middleware.WithResourceMiddleware(entry.Name, server.ResourceHandlerFunc(entry.Value.GetHandler()))
+		srv.AddResourceTemplate(templateDef, server.ResourceTemplateHandlerFunc(handler))
+		status.RecordResourceTemplate(entry.Name)
+		if st, ok := entry.Value.(subscriptions.Stater); ok && subs != nil {
+			subs.WatchTemplate(templateDef.URITemplate, st)
+		}
+	}
+	return nil
+}
+
+// checkResources is the startup self-check: each enabled resource is read once, straight from its
+// handler, and held to the same rules as WithResourceConsistency
+// templates are skipped - there's no telling which of their URIs will ever be read
+func checkResources(cfg *config.Config) error {
+	if middleware.GlobalSettings.ResourceConsistency == middleware.ResourceConsistencyOff {
+		return nil
+	}
+	entries, err := resources.Registered()
+	if err != nil {
+		return fmt.Errorf("resource registry: %w", err)
+	}
+
+	failed := 0
+	for _, entry := range entries {
+		if !cfg.Resources.Allows(entry.Name, entry.Tags, entry.Enabled) {
+			continue
+		}
+		def := entry.Value.GetResource()
+		var problems []string
+		if st, ok := entry.Value.(subscriptions.Stater); ok {
+			if _, err := st.Stat(def.URI); errors.Is(err, fs.ErrNotExist) {
+				problems = append(problems, "backing file is missing")
+			}
+		}
+		if len(problems) == 0 {
+			req := mcp.ReadResourceRequest{}
+			req.Params.URI = def.URI
+			contents, err := entry.Value.GetHandler()(context.Background(), req)
+			if err != nil {
+				problems = append(problems, fmt.Sprintf("read failed: %v", err))
+			} else {
+				problems = middleware.CheckResourceContents(def.URI, def.MIMEType, contents)
+			}
+		}
+		if len(problems) > 0 {
+			failed++
+			slog.Warn("resource failed the startup check", "component", "resource", "resource", def.URI, "problems", strings.Join(problems, "; "))
+		}
+	}
+
+	if failed > 0 && middleware.GlobalSettings.ResourceConsistency == middleware.ResourceConsistencyStrict {
+		return fmt.Errorf("%d resources failed the startup check; see the log, or set middleware.resource_consistency to warn", failed)
+	}
+	log.Printf("Checked resources: %d with problems", failed)
+	return nil
 }
 
 // registerPrompts sets up conversation templates for AI to use
 // think of these as conversation starters or script templates
-func registerPrompts(srv *server.MCPServer, prompts ...prompts.Prompt) {
-	for _, prompt := range prompts {
-		promptDef := prompt.GetPrompt() // what kind of prompt is this?
-		handler := prompt.GetHandler()  // how do we generate the template?
+func registerPrompts(srv *server.MCPServer, cfg *config.Config) error {
+	entries, err := prompts.Registered()
+	if err != nil {
+		return fmt.Errorf("prompt registry: %w", err)
+	}
+
+	for _, entry := range entries {
+		if !cfg.Prompts.Allows(entry.Name, entry.Tags, entry.Enabled) {
+			log.Printf("Skipping disabled prompt: %s", entry.Name)
+			continue
+		}
+		promptDef := entry.Value.GetPrompt() // what kind of prompt is this?
+		handler := entry.Value.GetHandler()  // how do we generate the template?
+
+		if s, ok := entry.Value.(prompts.SensitivePrompt); ok {
+			middleware.DeclareSensitivePromptArgs(promptDef.Name, s.SensitiveArguments()...)
+		}
 
 		// middleware wrapping - because we love consistency!
 		wrappedHandler := middleware.WithPromptMiddleware(promptDef.Name, handler)
 
 		// register the prompt so AI can use our templates
 		srv.AddPrompt(promptDef, wrappedHandler)
+		status.RecordPrompt(promptDef.Name)
 	}
+	return nil
 }
@@ -0,0 +1,107 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"slices"
+	"sync"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/suramrit/hello-mcp/auth"
+)
+
+// activePolicy is the authorization policy every call and list is checked against
+// nil means no policy: everyone may use everything, like before policies existed
+var activePolicy struct {
+	sync.RWMutex
+	policy *auth.Policy
+}
+
+// setPolicy installs the authorization policy; call it before serving traffic
+func SetPolicy(p *auth.Policy) {
+	activePolicy.Lock()
+	defer activePolicy.Unlock()
+	activePolicy.policy = p
+}
+
+// authorized reports whether the caller in ctx may use a capability
+// kind is "tool", "resource" or "prompt"; with no policy installed the answer is always yes
+func Authorized(ctx context.Context, kind, name string) bool {
+	activePolicy.RLock()
+	p := activePolicy.policy
+	activePolicy.RUnlock()
+	if p == nil {
+		return true
+	}
+	id, ok := auth.FromContext(ctx)
+	return p.Allows(id, ok, kind, name)
+}
+
+// denied logs a refused call; who asked and for what is exactly what an audit wants to see
+func denied(ctx context.Context, kind, name string) {
+	caller := "anonymous"
+	if id, ok := auth.FromContext(ctx); ok {
+		caller = id.Subject
+	}
+	slog.WarnContext(ctx, kind+" call denied by policy", "component", kind, kind, name, "caller", caller)
+}
+
+// withToolAuthorization refuses tool calls the policy doesn't grant the caller
+// the answer is the same as for a tool that doesn't exist, so probing learns nothing
+func WithToolAuthorization(name string, handler server.ToolHandlerFunc) server.ToolHandlerFunc {
+	return func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		if !Authorized(ctx, "tool", name) {
+			denied(ctx, "tool", name)
+			SetOutcome(ctx, OutcomeDenied)
+			return mcp.NewToolResultError(fmt.Sprintf("Tool %s not found", name)), nil
+		}
+		return handler(ctx, req)
+	}
+}
+
+// withResourceAuthorization refuses reads the policy doesn't grant the caller
+// for a template, granting the template grants every URI it matches; granting
+// a narrower URI pattern lets just those through
+func WithResourceAuthorization(uri string, handler server.ResourceHandlerFunc) server.ResourceHandlerFunc {
+	return func(ctx context.Context, req mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+		if !Authorized(ctx, "resource", uri) && (req.Params.URI == uri || !Authorized(ctx, "resource", req.Params.URI)) {
+			denied(ctx, "resource", uri)
+			return nil, fmt.Errorf("resource %s not found", req.Params.URI)
+		}
+		return handler(ctx, req)
+	}
+}
+
+// withPromptAuthorization refuses prompts the policy doesn't grant the caller
+func WithPromptAuthorization(name string, handler server.PromptHandlerFunc) server.PromptHandlerFunc {
+	return func(ctx context.Context, req mcp.GetPromptRequest) (*mcp.GetPromptResult, error) {
+		if !Authorized(ctx, "prompt", name) {
+			denied(ctx, "prompt", name)
+			return nil, fmt.Errorf("prompt %s not found", name)
+		}
+		return handler(ctx, req)
+	}
+}
+
+// filterTools hides tools the caller may not use from tools/list - plug it into server.WithToolFilter
+func FilterTools(ctx context.Context, tools []mcp.Tool) []mcp.Tool {
+	return slices.DeleteFunc(tools, func(t mcp.Tool) bool { return !Authorized(ctx, "tool", t.Name) })
+}
+
+// addListFilters hides resources, resource templates and prompts the caller may not use from their
+// list results - the SDK has a filter option for tools only, so the rest are trimmed after the fact
+func AddListFilters(hooks *server.Hooks) {
+	hooks.AddAfterListResources(func(ctx context.Context, id any, req *mcp.ListResourcesRequest, result *mcp.ListResourcesResult) {
+		result.Resources = slices.DeleteFunc(result.Resources, func(r mcp.Resource) bool { return !Authorized(ctx, "resource", r.URI) })
+	})
+	hooks.AddAfterListResourceTemplates(func(ctx context.Context, id any, req *mcp.ListResourceTemplatesRequest, result *mcp.ListResourceTemplatesResult) {
+		result.ResourceTemplates = slices.DeleteFunc(result.ResourceTemplates, func(t mcp.ResourceTemplate) bool {
+			return t.URITemplate == nil || !Authorized(ctx, "resource", t.URITemplate.Raw())
+		})
+	})
+	hooks.AddAfterListPrompts(func(ctx context.Context, id any, req *mcp.ListPromptsRequest, result *mcp.ListPromptsResult) {
+		result.Prompts = slices.DeleteFunc(result.Prompts, func(p mcp.Prompt) bool { return !Authorized(ctx, "prompt", p.Name) })
+	})
+}
@@ -0,0 +1,178 @@
+package middleware
+
+import (
+	"context"
+	"sync"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// handler is any of the three MCP handler types we know how to wrap
+type Handler interface {
+	server.ToolHandlerFunc | server.ResourceHandlerFunc | server.PromptHandlerFunc
+}
+
+// middleware wraps a handler for one capability; name is the tool/prompt name or resource URI
+// every With* function in this package already has this shape
+type Middleware[H Handler] func(name string, next H) H
+
+// chain is an ordered list of middleware for one kind of handler
+// the first middleware added is the outermost - it sees the call first and the result last
+type Chain[H Handler] struct {
+	mu     sync.RWMutex
+	layers []Middleware[H]
+	byName map[string][]Middleware[H] // extra layers for a single capability, innermost of all
+}
+
+// newChain creates a chain starting with the given middleware
+func NewChain[H Handler](layers ...Middleware[H]) *Chain[H] {
+	return &Chain[H]{
+		layers: layers,
+		byName: make(map[string][]Middleware[H]),
+	}
+}
+
+// use appends middleware that applies to every capability of this kind
+func (c *Chain[H]) Use(layers ...Middleware[H]) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.layers = append(c.layers, layers...)
+}
+
+// useFor appends middleware that applies to one capability only
+// handy for caching one expensive tool or guarding one sensitive resource
+func (c *Chain[H]) UseFor(name string, layers ...Middleware[H]) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.byName[name] = append(c.byName[name], layers...)
+}
+
+// then wraps a handler with everything in the chain
+// middleware is applied at registration time, so add layers before registering capabilities
+func (c *Chain[H]) Then(name string, handler H) H {
+	c.mu.RLock()
+	layers := append(append([]Middleware[H](nil), c.layers...), c.byName[name]...)
+	c.mu.RUnlock()
+
+	// wrap from the inside out so the first layer ends up outermost
+	h := handler
+	for i := len(layers) - 1; i >= 0; i-- {
+		h = layers[i](name, h)
+	}
+	return h
+}
+
+// when makes a layer conditional on a setting that's checked at wrap time
+// this is how GlobalSettings switches built-in layers on and off
+func When[H Handler](enabled func() bool, mw Middleware[H]) Middleware[H] {
+	return func(name string, next H) H {
+		if !enabled() {
+			return next
+		}
+		return mw(name, next)
+	}
+}
+
+// the default chains - built-in layers first, so anything added with Use runs inside
+// them and still gets tracing, metrics, logging, authorization, limits and panic recovery for free
+var (
+	Tools = NewChain(
+		When(func() bool { return GlobalSettings.Tracing }, WithToolTracing), // outermost, so the span covers every other layer
+		When(func() bool { return GlobalSettings.Metrics }, WithToolMetrics),
+		When(func() bool { return GlobalSettings.Logging }, WithToolLogging),
+		WithToolAuthorization, // callers only reach tools the policy grants them
+		WithToolPolicy,        // refused calls are refused before anything else looks at them
+		When(func() bool { return GlobalSettings.Validation }, WithToolValidation), // bad calls shouldn't spend rate-limit tokens
+		WithToolLimits,  // outside the timeout, so waiting in the queue doesn't eat the tool's deadline
+		WithToolTimeout, // outside recovery, so a panic in the handler goroutine is still caught
+		When(func() bool { return GlobalSettings.Recovery }, WithToolRecovery),
+		When(func() bool { return GlobalSettings.OutputValidation != OutputValidationOff }, WithToolOutputValidation),
+	)
+	Resources = NewChain(
+		When(func() bool { return GlobalSettings.Tracing }, WithResourceTracing),
+		When(func() bool { return GlobalSettings.Metrics }, WithResourceMetrics),
+		When(func() bool { return GlobalSettings.Logging }, WithResourceLogging),
+		WithResourceAuthorization,
+		When(func() bool { return GlobalSettings.Recovery }, WithResourceRecovery),
+		When(func() bool { return GlobalSettings.ResourceConsistency != ResourceConsistencyOff }, WithResourceConsistency),
+	)
+	Prompts = NewChain(
+		When(func() bool { return GlobalSettings.Tracing }, WithPromptTracing),
+		When(func() bool { return GlobalSettings.Metrics }, WithPromptMetrics),
+		When(func() bool { return GlobalSettings.Logging }, WithPromptLogging),
+		WithPromptAuthorization,
+		When(func() bool { return GlobalSettings.Recovery }, WithPromptRecovery),
+	)
+)
+
+// callInfo describes a call to any kind of capability
+// it's what type-agnostic middleware gets to look at
+type CallInfo struct {
+	Kind      string // "tool", "resource" or "prompt"
+	Name      string // tool/prompt name or resource URI
+	Arguments any    // tool arguments, prompt arguments, or nil for resources
+}
+
+// around is middleware that doesn't care what kind of capability it wraps
+// it runs code before and after next; returning an error without calling next rejects the call
+// perfect for auth and tracing, which look the same for tools, resources and prompts
+type Around func(ctx context.Context, call CallInfo, next func(context.Context) error) error
+
+// useGlobal adds type-agnostic middleware to the tool, resource and prompt chains at once
+func UseGlobal(a Around) {
+	Tools.Use(aroundTool(a))
+	Resources.Use(aroundResource(a))
+	Prompts.Use(aroundPrompt(a))
+}
+
+// aroundTool adapts an Around to a tool middleware
+func aroundTool(a Around) Middleware[server.ToolHandlerFunc] {
+	return func(name string, next server.ToolHandlerFunc) server.ToolHandlerFunc {
+		return func(ctx context.Context, req mcp.CallToolRequest) (result *mcp.CallToolResult, err error) {
+			call := CallInfo{Kind: "tool", Name: name, Arguments: req.Params.Arguments}
+			aroundErr := a(ctx, call, func(ctx context.Context) error {
+				result, err = next(ctx, req)
+				return err
+			})
+			if aroundErr != nil {
+				return nil, aroundErr
+			}
+			return result, err
+		}
+	}
+}
+
+// aroundResource adapts an Around to a resource middleware
+func aroundResource(a Around) Middleware[server.ResourceHandlerFunc] {
+	return func(uri string, next server.ResourceHandlerFunc) server.ResourceHandlerFunc {
+		return func(ctx context.Context, req mcp.ReadResourceRequest) (contents []mcp.ResourceContents, err error) {
+			call := CallInfo{Kind: "resource", Name: uri}
+			aroundErr := a(ctx, call, func(ctx context.Context) error {
+				contents, err = next(ctx, req)
+				return err
+			})
+			if aroundErr != nil {
+				return nil, aroundErr
+			}
+			return contents, err
+		}
+	}
+}
+
+// aroundPrompt adapts an Around to a prompt middleware
+func aroundPrompt(a Around) Middleware[server.PromptHandlerFunc] {
+	return func(name string, next server.PromptHandlerFunc) server.PromptHandlerFunc {
+		return func(ctx context.Context, req mcp.GetPromptRequest) (result *mcp.GetPromptResult, err error) {
+			call := CallInfo{Kind: "prompt", Name: name, Arguments: req.Params.Arguments}
+			aroundErr := a(ctx, call, func(ctx context.Context) error {
+				result, err = next(ctx, req)
+				return err
+			})
+			if aroundErr != nil {
+				return nil, aroundErr
+			}
+			return result, err
+		}
+	}
+}
@@ -0,0 +1,114 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"mime"
+	"strings"
+	"sync"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// resource consistency modes - warn in production, where a mislabelled read beats a failed one
+const (
+	ResourceConsistencyOff    = "off"    // trust the resources
+	ResourceConsistencyWarn   = "warn"   // log contents that don't match what was asked for or declared, but deliver them
+	ResourceConsistencyStrict = "strict" // replace them with an error, and refuse to start with a broken resource
+)
+
+// resourceMIMETypes holds the MIME type each resource or template declared, by URI or URI template
+var resourceMIMETypes = struct {
+	sync.RWMutex
+	byName map[string]string
+}{byName: make(map[string]string)}
+
+// declareResourceMIMEType remembers what a resource says it returns; an empty type promises nothing
+func DeclareResourceMIMEType(name, mimeType string) {
+	resourceMIMETypes.Lock()
+	defer resourceMIMETypes.Unlock()
+	resourceMIMETypes.byName[name] = mimeType
+}
+
+// declaredMIMEType looks up what DeclareResourceMIMEType was told
+func declaredMIMEType(name string) string {
+	resourceMIMETypes.RLock()
+	defer resourceMIMETypes.RUnlock()
+	return resourceMIMETypes.byName[name]
+}
+
+// withResourceConsistency checks that every item a read returns carries the URI that was read
+// and the MIME type the resource declared - clients cache and render by both
+// what happens on a mismatch depends on GlobalSettings.ResourceConsistency
+func WithResourceConsistency(uri string, handler server.ResourceHandlerFunc) server.ResourceHandlerFunc {
+	return func(ctx context.Context, req mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+		contents, err := handler(ctx, req)
+		if err != nil {
+			return contents, err
+		}
+
+		problems := CheckResourceContents(req.Params.URI, declaredMIMEType(uri), contents)
+		if len(problems) == 0 {
+			return contents, nil
+		}
+		slog.WarnContext(ctx, "resource contents do not match the request", "component", "resource", "resource", uri, "problems", strings.Join(problems, "; "))
+		if GlobalSettings.ResourceConsistency != ResourceConsistencyStrict {
+			return contents, nil
+		}
+		return nil, fmt.Errorf("resource %s returned inconsistent contents: %s", uri, strings.Join(problems, "; "))
+	}
+}
+
+// checkResourceContents lists how contents differ from a read of uri declared as mimeType
+// main's startup check uses it too, so both report mismatches the same way
+func CheckResourceContents(uri, mimeType string, contents []mcp.ResourceContents) []string {
+	if len(contents) == 0 {
+		return []string{"no contents"}
+	}
+
+	var problems []string
+	for i, c := range contents {
+		gotURI, gotType, ok := contentsLabel(c)
+		if !ok {
+			problems = append(problems, fmt.Sprintf("item %d: unknown contents type %T", i, c))
+			continue
+		}
+		if gotURI != uri {
+			problems = append(problems, fmt.Sprintf("item %d: URI is %q, want %q", i, gotURI, uri))
+		}
+		if mimeType != "" && !sameMediaType(gotType, mimeType) {
+			problems = append(problems, fmt.Sprintf("item %d: MIME type is %q, want %q", i, gotType, mimeType))
+		}
+	}
+	return problems
+}
+
+// contentsLabel pulls the URI and MIME type out of either kind of contents
+func contentsLabel(c mcp.ResourceContents) (uri, mimeType string, ok bool) {
+	switch c := c.(type) {
+	case mcp.TextResourceContents:
+		return c.URI, c.MIMEType, true
+	case *mcp.TextResourceContents:
+		return c.URI, c.MIMEType, true
+	case mcp.BlobResourceContents:
+		return c.URI, c.MIMEType, true
+	case *mcp.BlobResourceContents:
+		return c.URI, c.MIMEType, true
+	}
+	return "", "", false
+}
+
+// sameMediaType compares MIME types the way clients do: case-insensitively, and parameters
+// like charset don't count - "text/plain; charset=utf-8" is still text/plain
+func sameMediaType(a, b string) bool {
+	return mediaType(a) == mediaType(b)
+}
+
+func mediaType(t string) string {
+	if mt, _, err := mime.ParseMediaType(t); err == nil {
+		return mt
+	}
+	return strings.ToLower(strings.TrimSpace(t))
+}
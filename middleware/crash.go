@@ -0,0 +1,105 @@
+package middleware
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"runtime/debug"
+	"time"
+)
+
+// crashReport is everything we know about a panic, written as one JSON file per crash
+// the crash ID in the client's error message is the file name, so a bug report leads straight here
+type crashReport struct {
+	ID            string    `json:"id"`
+	Time          time.Time `json:"time"`
+	Kind          string    `json:"kind"` // tool, resource or prompt
+	Name          string    `json:"name"` // tool/prompt name or resource URI
+	CorrelationID string    `json:"correlation_id,omitempty"`
+	Panic         string    `json:"panic"`
+	Arguments     any       `json:"arguments,omitempty"` // redacted exactly like the logs
+	Stack         string    `json:"stack"`
+	Build         buildInfo `json:"build"`
+}
+
+// buildInfo pins a crash to the binary that produced it
+type buildInfo struct {
+	GoVersion string            `json:"go_version"`
+	Module    string            `json:"module"`
+	Version   string            `json:"version"`
+	Settings  map[string]string `json:"settings,omitempty"` // vcs.revision, vcs.modified, GOOS, GOARCH...
+}
+
+// recordPanic handles a recovered panic: count it, log it with its stack, and write a crash report
+// it must be called from the deferred function that recovered, so the stack still shows the culprit
+// the returned crash ID is safe to show the client - it reveals nothing but where to look
+func recordPanic(ctx context.Context, kind, name string, args any, recovered any) string {
+	report := crashReport{
+		ID:            newCorrelationID(),
+		Time:          time.Now().UTC(),
+		Kind:          kind,
+		Name:          name,
+		CorrelationID: CorrelationID(ctx),
+		Panic:         fmt.Sprint(recovered),
+		Arguments:     args,
+		Stack:         string(debug.Stack()),
+		Build:         currentBuild(),
+	}
+
+	GlobalMetrics.mu.Lock()
+	switch kind {
+	case "tool":
+		GlobalMetrics.ToolPanics[name]++
+	case "resource":
+		GlobalMetrics.ResourcePanics[name]++
+	case "prompt":
+		GlobalMetrics.PromptPanics[name]++
+	}
+	GlobalMetrics.mu.Unlock()
+
+	attrs := []any{"component", kind, kind, name, "panic", report.Panic, "crash_id", report.ID, "stack", report.Stack}
+	if path, err := writeCrashReport(GlobalSettings.CrashDir, report); err != nil {
+		attrs = append(attrs, "crash_report_error", err)
+	} else if path != "" {
+		attrs = append(attrs, "crash_report", path)
+	}
+	slog.ErrorContext(ctx, kind+" panicked", attrs...)
+	return report.ID
+}
+
+// writeCrashReport saves a report as <dir>/crash-<time>-<id>.json; an empty dir means logs only
+func writeCrashReport(dir string, report crashReport) (string, error) {
+	if dir == "" {
+		return "", nil
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", err
+	}
+	b, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	path := filepath.Join(dir, fmt.Sprintf("crash-%s-%s.json", report.Time.Format("20060102T150405Z"), report.ID))
+	return path, os.WriteFile(path, b, 0o644)
+}
+
+// currentBuild reads the build info the Go toolchain stamps into every binary
+func currentBuild() buildInfo {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return buildInfo{}
+	}
+	b := buildInfo{
+		GoVersion: info.GoVersion,
+		Module:    info.Main.Path,
+		Version:   info.Main.Version,
+		Settings:  make(map[string]string, len(info.Settings)),
+	}
+	for _, s := range info.Settings {
+		b.Settings[s.Key] = s.Value
+	}
+	return b
+}
@@ -0,0 +1,137 @@
+package middleware
+
+import (
+	"fmt"
+	"time"
+)
+
+// defaultLatencyBuckets are the upper bounds (in seconds) of our latency histograms
+// they span 1ms to 10s, which covers everything from echo to a slow network call
+var DefaultLatencyBuckets = []float64{0.001, 0.0025, 0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+// histogram counts observations into fixed latency buckets
+// unlike a summed duration, this tells p50 apart from p99
+// it is not safe for concurrent use on its own - Metrics guards it with its mutex
+type Histogram struct {
+	Bounds []float64     // bucket upper bounds in seconds, ascending
+	Counts []uint64      // observations per bucket; the extra last slot is the +Inf bucket
+	Sum    float64       // total of all observations in seconds
+	Count  uint64        // number of observations
+	Min    time.Duration // fastest observation seen
+	Max    time.Duration // slowest observation seen
+}
+
+// latencyStats is the human-friendly summary of a histogram, in milliseconds
+// percentiles are estimated from the buckets, so they're only as precise as the bucket layout
+type LatencyStats struct {
+	Count  uint64  `json:"count"`
+	MinMS  float64 `json:"min_ms"`
+	MaxMS  float64 `json:"max_ms"`
+	MeanMS float64 `json:"mean_ms"`
+	P50MS  float64 `json:"p50_ms"`
+	P90MS  float64 `json:"p90_ms"`
+	P99MS  float64 `json:"p99_ms"`
+}
+
+// newHistogram creates an empty histogram with the given bucket bounds
+func NewHistogram(bounds []float64) *Histogram {
+	return &Histogram{
+		Bounds: bounds,
+		Counts: make([]uint64, len(bounds)+1),
+	}
+}
+
+// validateBuckets makes sure custom bucket bounds are usable
+// Prometheus rejects histograms whose bounds aren't strictly increasing
+func ValidateBuckets(bounds []float64) error {
+	if len(bounds) == 0 {
+		return fmt.Errorf("at least one bucket is required")
+	}
+	for i, b := range bounds {
+		if b <= 0 {
+			return fmt.Errorf("bucket %d (%g) must be positive", i, b)
+		}
+		if i > 0 && b <= bounds[i-1] {
+			return fmt.Errorf("bucket %d (%g) must be greater than bucket %d (%g)", i, b, i-1, bounds[i-1])
+		}
+	}
+	return nil
+}
+
+// observe records one duration in the first bucket that fits it
+func (h *Histogram) Observe(d time.Duration) {
+	v := d.Seconds()
+	i := 0
+	for i < len(h.Bounds) && v > h.Bounds[i] {
+		i++
+	}
+	h.Counts[i]++
+	h.Sum += v
+	if h.Count == 0 || d < h.Min {
+		h.Min = d
+	}
+	if d > h.Max {
+		h.Max = d
+	}
+	h.Count++
+}
+
+// quantile estimates the q-th quantile (0 < q <= 1) in seconds
+// it finds the bucket holding the target rank and interpolates linearly inside it,
+// using the observed min and max to tighten the outermost buckets
+func (h *Histogram) Quantile(q float64) float64 {
+	if h.Count == 0 {
+		return 0
+	}
+	minS, maxS := h.Min.Seconds(), h.Max.Seconds()
+	rank := q * float64(h.Count)
+
+	var cumulative float64
+	for i, n := range h.Counts {
+		if n == 0 {
+			continue
+		}
+		if cumulative+float64(n) < rank {
+			cumulative += float64(n)
+			continue
+		}
+
+		lo, hi := minS, maxS
+		if i > 0 && h.Bounds[i-1] > lo {
+			lo = h.Bounds[i-1]
+		}
+		if i < len(h.Bounds) && h.Bounds[i] < hi {
+			hi = h.Bounds[i]
+		}
+		return lo + (hi-lo)*(rank-cumulative)/float64(n)
+	}
+	return maxS
+}
+
+// stats summarizes the histogram for GetStats
+func (h *Histogram) Stats() LatencyStats {
+	if h.Count == 0 {
+		return LatencyStats{}
+	}
+	return LatencyStats{
+		Count:  h.Count,
+		MinMS:  toMillis(h.Min.Seconds()),
+		MaxMS:  toMillis(h.Max.Seconds()),
+		MeanMS: toMillis(h.Sum / float64(h.Count)),
+		P50MS:  toMillis(h.Quantile(0.50)),
+		P90MS:  toMillis(h.Quantile(0.90)),
+		P99MS:  toMillis(h.Quantile(0.99)),
+	}
+}
+
+func toMillis(seconds float64) float64 {
+	return seconds * 1000
+}
+
+// clone makes a copy so readers can use it without holding the metrics lock
+func (h *Histogram) clone() *Histogram {
+	c := *h
+	c.Counts = make([]uint64, len(h.Counts))
+	copy(c.Counts, h.Counts)
+	return &c
+}
@@ -0,0 +1,260 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"math"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// toolLimits says how hard a tool may be hammered
+// zero values mean "no limit", so an empty ToolLimits changes nothing
+type ToolLimits struct {
+	MaxConcurrent int           // calls allowed to run at once
+	QueueTimeout  time.Duration // how long a call waits for a free slot before giving up; 0 = don't wait
+	Rate          float64       // sustained calls per second across all clients
+	Burst         int           // calls allowed in a burst above Rate (defaults to 1)
+	SessionRate   float64       // sustained calls per second for each client session
+	SessionBurst  int           // burst for each client session (defaults to 1)
+}
+
+// limiter is the runtime state behind one tool's ToolLimits
+type limiter struct {
+	limits   ToolLimits
+	slots    chan struct{} // semaphore; nil when concurrency is unlimited
+	global   *tokenBucket  // nil when there's no global rate
+	mu       sync.Mutex
+	sessions map[string]*tokenBucket // per-session buckets, created on first call
+}
+
+// toolLimiters holds the limiter for every tool that has limits
+var toolLimiters = struct {
+	sync.RWMutex
+	byName map[string]*limiter
+}{byName: make(map[string]*limiter)}
+
+// setToolLimits installs concurrency and rate limits for a tool
+// call it at registration time, before the tool sees traffic
+func SetToolLimits(tool string, l ToolLimits) {
+	lim := &limiter{limits: l, sessions: make(map[string]*tokenBucket)}
+	if l.MaxConcurrent > 0 {
+		lim.slots = make(chan struct{}, l.MaxConcurrent)
+	}
+	if l.Rate > 0 {
+		lim.global = newTokenBucket(l.Rate, l.Burst)
+	}
+
+	toolLimiters.Lock()
+	defer toolLimiters.Unlock()
+	toolLimiters.byName[tool] = lim
+}
+
+// rejection explains why a call was turned away and when to try again
+type rejection struct {
+	Error             string  `json:"error"`               // "rate_limited" or "concurrency_limited"
+	Scope             string  `json:"scope"`               // "global", "session" or "concurrency"
+	RetryAfterSeconds float64 `json:"retry_after_seconds"` // a hint, not a promise
+}
+
+// withToolLimits enforces a tool's rate and concurrency limits
+// like a bouncer with a clicker: too many people inside, or arriving too fast, and you wait outside
+func WithToolLimits(name string, handler server.ToolHandlerFunc) server.ToolHandlerFunc {
+	return func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		toolLimiters.RLock()
+		lim := toolLimiters.byName[name]
+		toolLimiters.RUnlock()
+		if lim == nil {
+			return handler(ctx, req) // no limits for this tool - come on in
+		}
+
+		// rate limits first - they're cheap and don't hold anything
+		if lim.global != nil {
+			if ok, wait := lim.global.take(); !ok {
+				return rejected(ctx, name, rejection{Error: "rate_limited", Scope: "global", RetryAfterSeconds: wait.Seconds()}), nil
+			}
+		}
+		if lim.limits.SessionRate > 0 {
+			if ok, wait := lim.sessionBucket(sessionID(ctx)).take(); !ok {
+				return rejected(ctx, name, rejection{Error: "rate_limited", Scope: "session", RetryAfterSeconds: wait.Seconds()}), nil
+			}
+		}
+
+		// then a slot - queueing for up to QueueTimeout if they're all taken
+		if lim.slots != nil {
+			if !lim.acquire(ctx) {
+				if ctx.Err() != nil {
+					SetOutcome(ctx, OutcomeCancelled)
+					return nil, ctx.Err() // the client gave up while queued
+				}
+				retry := lim.limits.QueueTimeout
+				if retry <= 0 {
+					retry = time.Second
+				}
+				return rejected(ctx, name, rejection{Error: "concurrency_limited", Scope: "concurrency", RetryAfterSeconds: retry.Seconds()}), nil
+			}
+			var lease *slotLease
+			ctx, lease = withSlotLease(ctx, func() { <-lim.slots })
+			defer func() {
+				if !lease.handedOff.Load() {
+					lease.release()
+				}
+			}()
+		}
+
+		return handler(ctx, req)
+	}
+}
+
+// slotLease is a held concurrency slot, passed down the chain so the timeout layer can keep it
+// when that layer answers early, the handler is still running - so the slot goes with the
+// handler's goroutine and is given back when the handler really returns, not when we stop waiting
+type slotLease struct {
+	once      sync.Once
+	free      func()
+	handedOff atomic.Bool // set by the timeout layer when it leaves the release to its goroutine
+}
+
+type slotLeaseKey struct{}
+
+// withSlotLease attaches a lease that gives the slot back through free, exactly once
+func withSlotLease(ctx context.Context, free func()) (context.Context, *slotLease) {
+	l := &slotLease{free: free}
+	return context.WithValue(ctx, slotLeaseKey{}, l), l
+}
+
+// slotLeaseFrom returns the call's lease, or nil if the tool has no concurrency limit
+func slotLeaseFrom(ctx context.Context) *slotLease {
+	l, _ := ctx.Value(slotLeaseKey{}).(*slotLease)
+	return l
+}
+
+// release gives the slot back; safe to call from both ends
+func (l *slotLease) release() {
+	l.once.Do(l.free)
+}
+
+// acquire grabs a concurrency slot, waiting up to QueueTimeout for one
+func (l *limiter) acquire(ctx context.Context) bool {
+	select {
+	case l.slots <- struct{}{}:
+		return true
+	default:
+	}
+	if l.limits.QueueTimeout <= 0 {
+		return false
+	}
+
+	timer := time.NewTimer(l.limits.QueueTimeout)
+	defer timer.Stop()
+	select {
+	case l.slots <- struct{}{}:
+		return true
+	case <-timer.C:
+		return false
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// sessionBucket returns (creating if needed) the token bucket for one client session
+// idle buckets that have refilled completely carry no information, so we drop them
+// once the map grows, which keeps long-running servers from leaking memory
+func (l *limiter) sessionBucket(id string) *tokenBucket {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	b, ok := l.sessions[id]
+	if !ok {
+		if len(l.sessions) >= 1024 {
+			for sid, sb := range l.sessions {
+				if sb.full() {
+					delete(l.sessions, sid)
+				}
+			}
+		}
+		b = newTokenBucket(l.limits.SessionRate, l.limits.SessionBurst)
+		l.sessions[id] = b
+	}
+	return b
+}
+
+// sessionID identifies the calling client; stdio has exactly one
+func sessionID(ctx context.Context) string {
+	if session := server.ClientSessionFromContext(ctx); session != nil {
+		return session.SessionID()
+	}
+	return ""
+}
+
+// rejected records the rejection and builds a tool error the client can act on
+// the text is for humans, the structured content is for agents deciding when to retry
+func rejected(ctx context.Context, name string, r rejection) *mcp.CallToolResult {
+	SetOutcome(ctx, OutcomeRejected)
+	GlobalMetrics.mu.Lock()
+	GlobalMetrics.ToolRejections[name]++
+	GlobalMetrics.mu.Unlock()
+
+	retry := int(math.Ceil(r.RetryAfterSeconds))
+	slog.WarnContext(ctx, "tool call rejected", "component", "tool", "tool", name, "reason", r.Error, "scope", r.Scope, "retry_after_seconds", retry)
+
+	var text string
+	if r.Error == "concurrency_limited" {
+		text = fmt.Sprintf("Tool %s is at its concurrency limit, retry after %ds", name, retry)
+	} else {
+		text = fmt.Sprintf("Tool %s rate limited (%s), retry after %ds", name, r.Scope, retry)
+	}
+	result := mcp.NewToolResultError(text)
+	result.StructuredContent = r
+	return result
+}
+
+// tokenBucket is the classic rate limiter: tokens drip in at rate per second,
+// each call spends one, and the bucket never holds more than burst
+type tokenBucket struct {
+	mu     sync.Mutex
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+func newTokenBucket(rate float64, burst int) *tokenBucket {
+	if burst < 1 {
+		burst = 1
+	}
+	return &tokenBucket{rate: rate, burst: float64(burst), tokens: float64(burst), last: time.Now()}
+}
+
+// refill tops up the bucket for the time that has passed; callers hold b.mu
+func (b *tokenBucket) refill(now time.Time) {
+	b.tokens = math.Min(b.burst, b.tokens+now.Sub(b.last).Seconds()*b.rate)
+	b.last = now
+}
+
+// take spends a token if there is one, otherwise says how long until there will be
+func (b *tokenBucket) take() (bool, time.Duration) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.refill(time.Now())
+	if b.tokens >= 1 {
+		b.tokens--
+		return true, 0
+	}
+	wait := time.Duration((1 - b.tokens) / b.rate * float64(time.Second))
+	return false, wait
+}
+
+// full reports whether the bucket has refilled completely
+func (b *tokenBucket) full() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.refill(time.Now())
+	return b.tokens >= b.burst
+}
@@ -0,0 +1,99 @@
+package middleware
+
+import (
+	"context"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// resultText is the text of a tool result's first content item
+func resultText(t *testing.T, result *mcp.CallToolResult) string {
+	t.Helper()
+	if result == nil || len(result.Content) == 0 {
+		t.Fatalf("result has no content: %+v", result)
+	}
+	text, ok := result.Content[0].(mcp.TextContent)
+	if !ok {
+		t.Fatalf("result content is %T, want text", result.Content[0])
+	}
+	return text.Text
+}
+
+// a handler that outlives its timeout keeps its concurrency slot until it really returns:
+// the slot is handed from the limits layer to the timeout layer's goroutine, not dropped
+// when the caller gets its answer
+func TestTimedOutCallKeepsItsSlot(t *testing.T) {
+	const tool = "test_timed_out_keeps_slot"
+	SetToolLimits(tool, ToolLimits{MaxConcurrent: 1})
+	SetToolTimeout(tool, 20*time.Millisecond)
+
+	var calls, running, peak atomic.Int32
+	unblock := make(chan struct{})
+	handler := func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		n := running.Add(1)
+		defer running.Add(-1)
+		if n > peak.Load() {
+			peak.Store(n)
+		}
+		if calls.Add(1) == 1 {
+			<-unblock // a stubborn handler that ignores ctx.Done()
+		}
+		return mcp.NewToolResultText("done"), nil
+	}
+	call := WithToolLimits(tool, WithToolTimeout(tool, handler))
+
+	result, err := call(context.Background(), mcp.CallToolRequest{})
+	if err != nil || !strings.Contains(resultText(t, result), "timed out") {
+		t.Fatalf("first call = %+v, %v; want a timeout", result, err)
+	}
+
+	// the first handler is still running, so there's no slot to be had
+	for i := 0; i < 3; i++ {
+		result, err = call(context.Background(), mcp.CallToolRequest{})
+		if err != nil || !strings.Contains(resultText(t, result), "concurrency limit") {
+			t.Fatalf("call while the timed-out handler runs = %+v, %v; want a concurrency rejection", result, err)
+		}
+	}
+	if got := calls.Load(); got != 1 {
+		t.Fatalf("%d handlers started while the slot was held, want 1", got)
+	}
+
+	// once it returns, the slot is free again
+	close(unblock)
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		result, err = call(context.Background(), mcp.CallToolRequest{})
+		if err == nil && resultText(t, result) == "done" {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("call after the handler returned = %+v, %v; the slot was never given back", result, err)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	if got := peak.Load(); got != 1 {
+		t.Errorf("%d handlers ran at once, want at most 1", got)
+	}
+}
+
+// a call that finishes in time gives its slot back on the way out, as if there were no timeout
+func TestCallWithinTimeoutReleasesSlot(t *testing.T) {
+	const tool = "test_within_timeout_releases_slot"
+	SetToolLimits(tool, ToolLimits{MaxConcurrent: 1})
+	SetToolTimeout(tool, time.Second)
+
+	handler := func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return mcp.NewToolResultText("done"), nil
+	}
+	call := WithToolLimits(tool, WithToolTimeout(tool, handler))
+	for i := 0; i < 3; i++ {
+		result, err := call(context.Background(), mcp.CallToolRequest{})
+		if err != nil || resultText(t, result) != "done" {
+			t.Fatalf("call %d = %+v, %v; want done", i, result, err)
+		}
+	}
+}
@@ -15,19 +15,28 @@ type Metrics struct {
 	mu sync.RWMutex // protects all the maps below from concurrent access chaos
 
 	// tool metrics - how are our tools performing?
-	ToolCalls     map[string]int64         // how many times each tool was called
-	ToolErrors    map[string]int64         // how many times each tool failed
-	ToolDurations map[string]time.Duration // total time spent in each tool
+	ToolCalls      map[string]int64             // how many times each tool was called
+	ToolErrors     map[string]int64             // validation, tool and internal errors - the failures that are the tool's (or caller's) fault
+	ToolOutcomes   map[string]map[Outcome]int64 // every call of each tool, by how it ended
+	ToolDurations  map[string]*Histogram        // latency distribution of each tool
+	ToolTimeouts   map[string]int64             // how many times each tool blew its deadline (not counted in ToolErrors)
+	ToolRejections map[string]int64             // calls turned away by rate or concurrency limits (not counted in ToolErrors)
+	ToolPanics     map[string]int64             // calls that panicked and were recovered (not counted in ToolErrors)
 
 	// resource metrics - how's our data access doing?
-	ResourceReads     map[string]int64         // how many times each resource was read
-	ResourceErrors    map[string]int64         // how many read failures we've had
-	ResourceDurations map[string]time.Duration // time spent reading resources
+	ResourceReads     map[string]int64      // how many times each resource was read
+	ResourceErrors    map[string]int64      // how many read failures we've had
+	ResourceDurations map[string]*Histogram // latency distribution of resource reads
+	ResourcePanics    map[string]int64      // reads that panicked (also counted in ResourceErrors - the client saw an error)
 
 	// prompt metrics - are our conversation templates popular?
-	PromptGets      map[string]int64         // how many times each prompt was requested
-	PromptErrors    map[string]int64         // prompt generation failures (shouldn't be many!)
-	PromptDurations map[string]time.Duration // time spent generating prompts
+	PromptGets      map[string]int64      // how many times each prompt was requested
+	PromptErrors    map[string]int64      // prompt generation failures (shouldn't be many!)
+	PromptDurations map[string]*Histogram // latency distribution of prompt generation
+	PromptPanics    map[string]int64      // gets that panicked (also counted in PromptErrors)
+
+	// latency bucket bounds in seconds for new histograms - set before serving traffic
+	LatencyBuckets []float64
 }
 
 // globalMetrics is our singleton metrics collector
@@ -35,13 +44,20 @@ type Metrics struct {
 var GlobalMetrics = &Metrics{
 	ToolCalls:         make(map[string]int64),
 	ToolErrors:        make(map[string]int64),
-	ToolDurations:     make(map[string]time.Duration),
+	ToolOutcomes:      make(map[string]map[Outcome]int64),
+	ToolDurations:     make(map[string]*Histogram),
+	ToolTimeouts:      make(map[string]int64),
+	ToolRejections:    make(map[string]int64),
+	ToolPanics:        make(map[string]int64),
 	ResourceReads:     make(map[string]int64),
 	ResourceErrors:    make(map[string]int64),
-	ResourceDurations: make(map[string]time.Duration),
+	ResourceDurations: make(map[string]*Histogram),
+	ResourcePanics:    make(map[string]int64),
 	PromptGets:        make(map[string]int64),
 	PromptErrors:      make(map[string]int64),
-	PromptDurations:   make(map[string]time.Duration),
+	PromptDurations:   make(map[string]*Histogram),
+	PromptPanics:      make(map[string]int64),
+	LatencyBuckets:    DefaultLatencyBuckets,
 }
 
 // withToolMetrics wraps tool handlers to collect performance data
@@ -49,6 +65,7 @@ var GlobalMetrics = &Metrics{
 func WithToolMetrics(name string, handler server.ToolHandlerFunc) server.ToolHandlerFunc {
 	return func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 		start := time.Now()
+		ctx, slot := ensureOutcome(ctx)
 
 		// increment the call counter - thread-safely!
 		GlobalMetrics.mu.Lock()
@@ -58,11 +75,16 @@ func WithToolMetrics(name string, handler server.ToolHandlerFunc) server.ToolHan
 		// do the actual work
 		result, err := handler(ctx, req)
 
-		// record how long it took and whether it succeeded
+		// record how long it took and how it ended - an IsError result is a failure too
 		duration := time.Since(start)
+		outcome := slot.classify(result, err)
 		GlobalMetrics.mu.Lock()
-		GlobalMetrics.ToolDurations[name] += duration
-		if err != nil {
+		GlobalMetrics.observe(GlobalMetrics.ToolDurations, name, duration)
+		if GlobalMetrics.ToolOutcomes[name] == nil {
+			GlobalMetrics.ToolOutcomes[name] = make(map[Outcome]int64)
+		}
+		GlobalMetrics.ToolOutcomes[name][outcome]++
+		if outcome.isError() {
 			GlobalMetrics.ToolErrors[name]++ // another one bites the dust
 		}
 		GlobalMetrics.mu.Unlock()
@@ -88,7 +110,7 @@ func WithResourceMetrics(uri string, handler server.ResourceHandlerFunc) server.
 		// record the results
 		duration := time.Since(start)
 		GlobalMetrics.mu.Lock()
-		GlobalMetrics.ResourceDurations[uri] += duration
+		GlobalMetrics.observe(GlobalMetrics.ResourceDurations, uri, duration)
 		if err != nil {
 			GlobalMetrics.ResourceErrors[uri]++ // file not found? permission denied? we'll know!
 		}
@@ -115,7 +137,7 @@ func WithPromptMetrics(name string, handler server.PromptHandlerFunc) server.Pro
 		// record the performance data
 		duration := time.Since(start)
 		GlobalMetrics.mu.Lock()
-		GlobalMetrics.PromptDurations[name] += duration
+		GlobalMetrics.observe(GlobalMetrics.PromptDurations, name, duration)
 		if err != nil {
 			GlobalMetrics.PromptErrors[name]++ // this really shouldn't happen often
 		}
@@ -125,22 +147,113 @@ func WithPromptMetrics(name string, handler server.PromptHandlerFunc) server.Pro
 	}
 }
 
+// observe adds a duration to the named histogram, creating it on first use
+// callers must hold the metrics write lock
+func (m *Metrics) observe(histograms map[string]*Histogram, name string, d time.Duration) {
+	h, ok := histograms[name]
+	if !ok {
+		h = NewHistogram(m.LatencyBuckets)
+		histograms[name] = h
+	}
+	h.Observe(d)
+}
+
+// metricsSnapshot is a consistent copy of the metrics, taken under one read lock
+// so a reader never sees a call counted but its duration missing
+type metricsSnapshot struct {
+	toolCalls, toolErrors         map[string]int64
+	toolTimeouts, toolRejections  map[string]int64
+	toolPanics, resourcePanics    map[string]int64
+	promptPanics                  map[string]int64
+	toolOutcomes                  map[string]map[Outcome]int64
+	resourceReads, resourceErrors map[string]int64
+	promptGets, promptErrors      map[string]int64
+	toolDurations                 map[string]*Histogram
+	resourceDurations             map[string]*Histogram
+	promptDurations               map[string]*Histogram
+}
+
+// snapshot copies everything so callers can read at leisure without holding the lock
+func (m *Metrics) snapshot() metricsSnapshot {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	return metricsSnapshot{
+		toolCalls:         copyCounts(m.ToolCalls),
+		toolErrors:        copyCounts(m.ToolErrors),
+		toolTimeouts:      copyCounts(m.ToolTimeouts),
+		toolRejections:    copyCounts(m.ToolRejections),
+		toolPanics:        copyCounts(m.ToolPanics),
+		toolOutcomes:      copyOutcomes(m.ToolOutcomes),
+		resourcePanics:    copyCounts(m.ResourcePanics),
+		promptPanics:      copyCounts(m.PromptPanics),
+		resourceReads:     copyCounts(m.ResourceReads),
+		resourceErrors:    copyCounts(m.ResourceErrors),
+		promptGets:        copyCounts(m.PromptGets),
+		promptErrors:      copyCounts(m.PromptErrors),
+		toolDurations:     copyHistograms(m.ToolDurations),
+		resourceDurations: copyHistograms(m.ResourceDurations),
+		promptDurations:   copyHistograms(m.PromptDurations),
+	}
+}
+
+func copyCounts(src map[string]int64) map[string]int64 {
+	dst := make(map[string]int64, len(src))
+	for k, v := range src {
+		dst[k] = v
+	}
+	return dst
+}
+
+func copyOutcomes(src map[string]map[Outcome]int64) map[string]map[Outcome]int64 {
+	dst := make(map[string]map[Outcome]int64, len(src))
+	for k, v := range src {
+		dst[k] = make(map[Outcome]int64, len(v))
+		for o, n := range v {
+			dst[k][o] = n
+		}
+	}
+	return dst
+}
+
+func copyHistograms(src map[string]*Histogram) map[string]*Histogram {
+	dst := make(map[string]*Histogram, len(src))
+	for k, v := range src {
+		dst[k] = v.clone()
+	}
+	return dst
+}
+
 // getStats returns a snapshot of all our performance metrics
 // this is like getting a report card for your server's performance
 func (m *Metrics) GetStats() map[string]interface{} {
-	m.mu.RLock()
-	defer m.mu.RUnlock()
+	s := m.snapshot()
 
 	// return everything we've collected - tools, resources, and prompts
 	return map[string]interface{}{
-		"tool_calls":         m.ToolCalls,         // how busy are our tools?
-		"tool_errors":        m.ToolErrors,        // how reliable are they?
-		"tool_durations":     m.ToolDurations,     // how fast are they?
-		"resource_reads":     m.ResourceReads,     // how much data are we serving?
-		"resource_errors":    m.ResourceErrors,    // any file access problems?
-		"resource_durations": m.ResourceDurations, // how fast is our I/O?
-		"prompt_gets":        m.PromptGets,        // are our templates popular?
-		"prompt_errors":      m.PromptErrors,      // any template generation issues?
-		"prompt_durations":   m.PromptDurations,   // how fast can we generate templates?
+		"tool_calls":         s.toolCalls,                       // how busy are our tools?
+		"tool_errors":        s.toolErrors,                      // how reliable are they?
+		"tool_outcomes":      s.toolOutcomes,                    // and when they fail, whose fault is it?
+		"tool_durations":     latencyStats(s.toolDurations),     // how fast are they - typically and at worst?
+		"tool_timeouts":      s.toolTimeouts,                    // who keeps blowing their deadline?
+		"tool_rejections":    s.toolRejections,                  // who's being called faster than they can cope?
+		"tool_panics":        s.toolPanics,                      // who's crashing? check the crash reports
+		"resource_reads":     s.resourceReads,                   // how much data are we serving?
+		"resource_errors":    s.resourceErrors,                  // any file access problems?
+		"resource_durations": latencyStats(s.resourceDurations), // how fast is our I/O?
+		"resource_panics":    s.resourcePanics,
+		"prompt_gets":        s.promptGets,                    // are our templates popular?
+		"prompt_errors":      s.promptErrors,                  // any template generation issues?
+		"prompt_durations":   latencyStats(s.promptDurations), // how fast can we generate templates?
+		"prompt_panics":      s.promptPanics,
+	}
+}
+
+// latencyStats summarizes every histogram in a map
+func latencyStats(histograms map[string]*Histogram) map[string]LatencyStats {
+	out := make(map[string]LatencyStats, len(histograms))
+	for name, h := range histograms {
+		out[name] = h.Stats()
 	}
+	return out
 }
@@ -3,11 +3,12 @@ package middleware
 import (
 	"context"
 	"fmt"
-	"log"
+	"log/slog"
 	"time"
 
 	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/mark3labs/mcp-go/server"
+	"github.com/suramrit/hello-mcp/auth"
 )
 
 // withToolLogging wraps tool handlers with comprehensive logging
@@ -15,22 +16,38 @@ import (
 func WithToolLogging(name string, handler server.ToolHandlerFunc) server.ToolHandlerFunc {
 	return func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 		start := time.Now()
-		// log the incoming request - what tool is being called and with what arguments
-		log.Printf("[TOOL] Starting: %s with args: %v", name, req.Params.Arguments)
+		ctx, _ = ensureCorrelationID(ctx) // every log line for this call shares one ID
+		ctx, slot := ensureOutcome(ctx)
+		logger := callLogger(ctx, "tool", name)
+
+		// log the incoming request - what tool is being called and with what (redacted) arguments
+		logger.InfoContext(ctx, "tool call started", "args", redactToolArgs(name, req.Params.Arguments))
 
 		// call the actual tool handler - this is where the real work happens
 		result, err := handler(ctx, req)
 
 		duration := time.Since(start)
+		outcome := slot.classify(result, err)
 		if err != nil {
 			// something went wrong - log it and return a safe error message
-			log.Printf("[TOOL] Error in %s (took %v): %v", name, duration, err)
+			logger.ErrorContext(ctx, "tool call failed", durationAttr(duration), "outcome", outcome, "error", err)
+			if outcome == OutcomeCancelled {
+				return nil, err // nobody is listening for a result anymore
+			}
 			// return a sanitized error message to the client - don't leak internal details!
 			return mcp.NewToolResultError(fmt.Sprintf("Tool %s failed: %v", name, err)), nil
 		}
 
-		// success! log how long it took
-		log.Printf("[TOOL] Completed: %s (took %v)", name, duration)
+		// an IsError result is still a failure, just one the tool reported politely
+		level := slog.LevelInfo
+		switch outcome {
+		case OutcomeSuccess:
+		case OutcomePanic, OutcomeInternalError:
+			level = slog.LevelError
+		default:
+			level = slog.LevelWarn
+		}
+		logger.Log(ctx, level, "tool call completed", durationAttr(duration), "outcome", outcome)
 		return result, nil
 	}
 }
@@ -40,7 +57,9 @@ func WithToolLogging(name string, handler server.ToolHandlerFunc) server.ToolHan
 func WithResourceLogging(uri string, handler server.ResourceHandlerFunc) server.ResourceHandlerFunc {
 	return func(ctx context.Context, req mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
 		start := time.Now()
-		log.Printf("[RESOURCE] Reading: %s", uri)
+		ctx, _ = ensureCorrelationID(ctx)
+		logger := callLogger(ctx, "resource", uri)
+		logger.InfoContext(ctx, "resource read started")
 
 		// attempt to read the resource
 		contents, err := handler(ctx, req)
@@ -48,12 +67,12 @@ func WithResourceLogging(uri string, handler server.ResourceHandlerFunc) server.
 		duration := time.Since(start)
 		if err != nil {
 			// resource couldn't be read - log the failure
-			log.Printf("[RESOURCE] Error reading %s (took %v): %v", uri, duration, err)
+			logger.ErrorContext(ctx, "resource read failed", durationAttr(duration), "error", err)
 			return nil, fmt.Errorf("failed to read resource %s: %w", uri, err)
 		}
 
 		// success! log what we accomplished
-		log.Printf("[RESOURCE] Successfully read: %s (took %v, %d items)", uri, duration, len(contents))
+		logger.InfoContext(ctx, "resource read completed", durationAttr(duration), "items", len(contents))
 		return contents, nil
 	}
 }
@@ -63,7 +82,9 @@ func WithResourceLogging(uri string, handler server.ResourceHandlerFunc) server.
 func WithPromptLogging(name string, handler server.PromptHandlerFunc) server.PromptHandlerFunc {
 	return func(ctx context.Context, req mcp.GetPromptRequest) (*mcp.GetPromptResult, error) {
 		start := time.Now()
-		log.Printf("[PROMPT] Getting: %s with args: %v", name, req.Params.Arguments)
+		ctx, _ = ensureCorrelationID(ctx)
+		logger := callLogger(ctx, "prompt", name)
+		logger.InfoContext(ctx, "prompt get started", "args", redactPromptArgs(name, req.Params.Arguments))
 
 		// generate the prompt template
 		result, err := handler(ctx, req)
@@ -71,25 +92,42 @@ func WithPromptLogging(name string, handler server.PromptHandlerFunc) server.Pro
 		duration := time.Since(start)
 		if err != nil {
 			// prompt generation failed - this shouldn't happen often
-			log.Printf("[PROMPT] Error in %s (took %v): %v", name, duration, err)
+			logger.ErrorContext(ctx, "prompt get failed", durationAttr(duration), "error", err)
 			return nil, fmt.Errorf("failed to get prompt %s: %w", name, err)
 		}
 
-		log.Printf("[PROMPT] Completed: %s (took %v)", name, duration)
+		logger.InfoContext(ctx, "prompt get completed", durationAttr(duration))
 		return result, nil
 	}
 }
 
+// callLogger tags every line of a call with what is being called and, on authenticated
+// transports, who is calling
+func callLogger(ctx context.Context, kind, name string) *slog.Logger {
+	logger := slog.Default().With("component", kind, kind, name)
+	if id, ok := auth.FromContext(ctx); ok {
+		logger = logger.With("caller", id.Subject)
+	}
+	return logger
+}
+
+// durationAttr reports durations in milliseconds - log pipelines prefer numbers over "1.2ms" strings
+func durationAttr(d time.Duration) slog.Attr {
+	return slog.Float64("duration_ms", float64(d)/float64(time.Millisecond))
+}
+
 // withToolRecovery catches panics in tool handlers
 // this is like having a safety net under a trapeze - if something goes horribly wrong, we catch it
+// the client gets a crash ID, we keep the stack and a crash report
 func WithToolRecovery(name string, handler server.ToolHandlerFunc) server.ToolHandlerFunc {
 	return func(ctx context.Context, req mcp.CallToolRequest) (result *mcp.CallToolResult, err error) {
 		// set up a panic recovery mechanism
 		defer func() {
 			if r := recover(); r != nil {
-				// something panicked! log it and return a safe error
-				log.Printf("[TOOL] PANIC in %s: %v", name, r)
-				result = mcp.NewToolResultError(fmt.Sprintf("Tool %s encountered an internal error", name))
+				// something panicked! record it and return a safe error
+				SetOutcome(ctx, OutcomePanic)
+				crashID := recordPanic(ctx, "tool", name, redactToolArgs(name, req.Params.Arguments), r)
+				result = mcp.NewToolResultError(fmt.Sprintf("Tool %s encountered an internal error (crash ID %s)", name, crashID))
 				err = nil // we handled the panic, so no error to return
 			}
 		}()
@@ -103,9 +141,9 @@ func WithResourceRecovery(uri string, handler server.ResourceHandlerFunc) server
 	return func(ctx context.Context, req mcp.ReadResourceRequest) (contents []mcp.ResourceContents, err error) {
 		defer func() {
 			if r := recover(); r != nil {
-				log.Printf("[RESOURCE] PANIC reading %s: %v", uri, r)
+				crashID := recordPanic(ctx, "resource", uri, nil, r)
 				contents = nil
-				err = fmt.Errorf("resource %s encountered an internal error", uri)
+				err = fmt.Errorf("resource %s encountered an internal error (crash ID %s)", uri, crashID)
 			}
 		}()
 		return handler(ctx, req)
@@ -118,32 +156,58 @@ func WithPromptRecovery(name string, handler server.PromptHandlerFunc) server.Pr
 	return func(ctx context.Context, req mcp.GetPromptRequest) (result *mcp.GetPromptResult, err error) {
 		defer func() {
 			if r := recover(); r != nil {
-				log.Printf("[PROMPT] PANIC in %s: %v", name, r)
+				crashID := recordPanic(ctx, "prompt", name, redactPromptArgs(name, req.Params.Arguments), r)
 				result = nil
-				err = fmt.Errorf("prompt %s encountered an internal error", name)
+				err = fmt.Errorf("prompt %s encountered an internal error (crash ID %s)", name, crashID)
 			}
 		}()
 		return handler(ctx, req)
 	}
 }
 
-// withToolMiddleware combines logging, recovery, and metrics middleware
-// this is the full safety package - logging, panic recovery, and performance tracking
+// settings toggles the individual layers of the middleware stack
+// handy when you want a quiet log or need to rule out a layer while debugging
+type Settings struct {
+	Logging    bool // record every call in the log
+	Recovery   bool // turn panics into errors instead of crashing the server
+	Metrics    bool // count calls, errors and durations in GlobalMetrics
+	Tracing    bool // open an OpenTelemetry span per call (off until an exporter is configured)
+	Validation bool // check tool arguments against the tool's input schema before calling it
+
+	DefaultToolTimeout  time.Duration // deadline for tools that don't declare their own; 0 means none
+	CrashDir            string        // where recovered panics leave crash reports; empty means log only
+	OutputValidation    string        // off, warn or strict: what to do with results that break their output schema
+	ResourceConsistency string        // off, warn or strict: what to do with resource contents labelled differently from the read
+
+	AllowDestructiveTools bool // serve tools whose annotations say they may destroy things
+}
+
+// globalSettings controls which layers the With*Middleware helpers apply
+// everything is on by default - you have to opt out of safety, not into it
+var GlobalSettings = Settings{
+	Logging:    true,
+	Recovery:   true,
+	Metrics:    true,
+	Validation: true,
+
+	OutputValidation:    OutputValidationOff,     // a debugging aid - see WithToolOutputValidation
+	ResourceConsistency: ResourceConsistencyWarn, // cheap enough to always look; see WithResourceConsistency
+}
+
+// withToolMiddleware wraps a tool handler with everything in the Tools chain
+// this is the full safety package - metrics, logging, argument checks, limits, deadlines and panic recovery
 func WithToolMiddleware(name string, handler server.ToolHandlerFunc) server.ToolHandlerFunc {
-	h := WithToolLogging(name, WithToolRecovery(name, handler))
-	return WithToolMetrics(name, h)
+	return Tools.Then(name, handler)
 }
 
-// withResourceMiddleware applies the full middleware stack to resources
+// withResourceMiddleware applies the Resources chain
 // because resources deserve the same level of care as tools
 func WithResourceMiddleware(uri string, handler server.ResourceHandlerFunc) server.ResourceHandlerFunc {
-	h := WithResourceLogging(uri, WithResourceRecovery(uri, handler))
-	return WithResourceMetrics(uri, h)
+	return Resources.Then(uri, handler)
 }
 
-// withPromptMiddleware applies the full middleware stack to prompts
+// withPromptMiddleware applies the Prompts chain
 // even conversation templates need proper monitoring
 func WithPromptMiddleware(name string, handler server.PromptHandlerFunc) server.PromptHandlerFunc {
-	h := WithPromptLogging(name, WithPromptRecovery(name, handler))
-	return WithPromptMetrics(name, h)
+	return Prompts.Then(name, handler)
 }
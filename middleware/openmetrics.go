@@ -0,0 +1,113 @@
+package middleware
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// openMetricsContentType is what Prometheus expects when scraping OpenMetrics text
+const openMetricsContentType = "application/openmetrics-text; version=1.0.0; charset=utf-8"
+
+// writeOpenMetrics renders the metrics in OpenMetrics text format
+// this is the bit Prometheus (or anything that speaks its format) scrapes
+func (m *Metrics) WriteOpenMetrics(w io.Writer) error {
+	s := m.snapshot()
+	bw := bufio.NewWriter(w)
+
+	writeCounter(bw, "mcp_tool_calls", "Total tool calls.", "tool", s.toolCalls)
+	writeCounter(bw, "mcp_tool_errors", "Tool calls that returned an error.", "tool", s.toolErrors)
+	writeCounter(bw, "mcp_tool_timeouts", "Tool calls that exceeded their deadline.", "tool", s.toolTimeouts)
+	writeCounter(bw, "mcp_tool_rejections", "Tool calls rejected by rate or concurrency limits.", "tool", s.toolRejections)
+	writeCounter(bw, "mcp_tool_panics", "Tool calls that panicked.", "tool", s.toolPanics)
+	writeOutcomes(bw, "mcp_tool_outcomes", "Tool calls by outcome.", "tool", s.toolOutcomes)
+	writeHistogram(bw, "mcp_tool_duration_seconds", "Tool call latency in seconds.", "tool", s.toolDurations)
+
+	writeCounter(bw, "mcp_resource_reads", "Total resource reads.", "resource", s.resourceReads)
+	writeCounter(bw, "mcp_resource_errors", "Resource reads that returned an error.", "resource", s.resourceErrors)
+	writeCounter(bw, "mcp_resource_panics", "Resource reads that panicked.", "resource", s.resourcePanics)
+	writeHistogram(bw, "mcp_resource_duration_seconds", "Resource read latency in seconds.", "resource", s.resourceDurations)
+
+	writeCounter(bw, "mcp_prompt_gets", "Total prompt requests.", "prompt", s.promptGets)
+	writeCounter(bw, "mcp_prompt_errors", "Prompt requests that returned an error.", "prompt", s.promptErrors)
+	writeCounter(bw, "mcp_prompt_panics", "Prompt requests that panicked.", "prompt", s.promptPanics)
+	writeHistogram(bw, "mcp_prompt_duration_seconds", "Prompt generation latency in seconds.", "prompt", s.promptDurations)
+
+	fmt.Fprintln(bw, "# EOF") // OpenMetrics requires the explicit end marker
+	return bw.Flush()
+}
+
+// metricsHandler serves GlobalMetrics for scrapers
+// mount it at /metrics on whatever port the ops team likes
+func MetricsHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", openMetricsContentType)
+		if err := GlobalMetrics.WriteOpenMetrics(w); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+	})
+}
+
+// writeCounter writes one counter family with a sample per name
+func writeCounter(w io.Writer, family, help, label string, values map[string]int64) {
+	fmt.Fprintf(w, "# TYPE %s counter\n# HELP %s %s\n", family, family, help)
+	for _, name := range sortedKeys(values) {
+		fmt.Fprintf(w, "%s_total{%s=%s} %d\n", family, label, quoteLabel(name), values[name])
+	}
+}
+
+// writeOutcomes writes one counter family with a sample per name and outcome class
+func writeOutcomes(w io.Writer, family, help, label string, values map[string]map[Outcome]int64) {
+	fmt.Fprintf(w, "# TYPE %s counter\n# HELP %s %s\n", family, family, help)
+	for _, name := range sortedKeys(values) {
+		for _, o := range outcomes {
+			if n, ok := values[name][o]; ok {
+				fmt.Fprintf(w, "%s_total{%s=%s,outcome=%s} %d\n", family, label, quoteLabel(name), quoteLabel(string(o)), n)
+			}
+		}
+	}
+}
+
+// writeHistogram writes one histogram family; buckets are cumulative as the format demands
+func writeHistogram(w io.Writer, family, help, label string, values map[string]*Histogram) {
+	fmt.Fprintf(w, "# TYPE %s histogram\n# HELP %s %s\n", family, family, help)
+	for _, name := range sortedKeys(values) {
+		h := values[name]
+		l := quoteLabel(name)
+
+		var cumulative uint64
+		for i, bound := range h.Bounds {
+			cumulative += h.Counts[i]
+			fmt.Fprintf(w, "%s_bucket{%s=%s,le=\"%s\"} %d\n", family, label, l, formatFloat(bound), cumulative)
+		}
+		cumulative += h.Counts[len(h.Bounds)]
+		fmt.Fprintf(w, "%s_bucket{%s=%s,le=\"+Inf\"} %d\n", family, label, l, cumulative)
+		fmt.Fprintf(w, "%s_sum{%s=%s} %s\n", family, label, l, formatFloat(h.Sum))
+		fmt.Fprintf(w, "%s_count{%s=%s} %d\n", family, label, l, h.Count)
+	}
+}
+
+// sortedKeys gives stable output - scrapers don't care, but humans diffing output do
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// quoteLabel escapes a label value per the exposition format
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func quoteLabel(v string) string {
+	return `"` + labelEscaper.Replace(v) + `"`
+}
+
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'g', -1, 64)
+}
@@ -0,0 +1,104 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// outcome is how a tool call ended, in words a dashboard can group by
+// "the handler returned" isn't the same as "it worked" - a tool error comes back as a result too
+type Outcome string
+
+const (
+	OutcomeSuccess         Outcome = "success"          // the tool did its job
+	OutcomeValidationError Outcome = "validation_error" // the caller sent bad arguments
+	OutcomeToolError       Outcome = "tool_error"       // the tool ran and reported a failure (IsError result)
+	OutcomeInternalError   Outcome = "internal_error"   // the handler returned a Go error
+	OutcomePanic           Outcome = "panic"            // the handler panicked and recovery caught it
+	OutcomeTimeout         Outcome = "timeout"          // the tool blew its deadline
+	OutcomeCancelled       Outcome = "cancelled"        // the client gave up first
+	OutcomeRejected        Outcome = "rejected"         // rate or concurrency limits turned the call away
+	OutcomeDenied          Outcome = "denied"           // the authorization policy doesn't grant the caller this tool
+)
+
+// outcomes lists every class in a stable order, for metrics output
+var outcomes = []Outcome{
+	OutcomeSuccess, OutcomeValidationError, OutcomeToolError, OutcomeInternalError,
+	OutcomePanic, OutcomeTimeout, OutcomeCancelled, OutcomeRejected, OutcomeDenied,
+}
+
+// outcomeKey is how the outcome slot travels in a context
+type outcomeKey struct{}
+
+// outcomeSlot is shared by every layer of one call: inner layers that know exactly what
+// happened (recovery, timeout, limits, the handler itself) fill it in, outer layers read it
+type outcomeSlot struct {
+	mu      sync.Mutex
+	outcome Outcome
+}
+
+// ensureOutcome reuses the call's outcome slot or creates one
+// the outermost layer that cares creates it; everyone inside shares it
+func ensureOutcome(ctx context.Context) (context.Context, *outcomeSlot) {
+	if slot, ok := ctx.Value(outcomeKey{}).(*outcomeSlot); ok {
+		return ctx, slot
+	}
+	slot := &outcomeSlot{}
+	return context.WithValue(ctx, outcomeKey{}, slot), slot
+}
+
+// setOutcome records how the call ended; the first layer to say so wins, because the
+// innermost layer knows best (a panic stays a panic, even though logging saw an error)
+// and a handler abandoned by a timeout can't rewrite history when it finally returns
+func SetOutcome(ctx context.Context, o Outcome) {
+	slot, ok := ctx.Value(outcomeKey{}).(*outcomeSlot)
+	if !ok {
+		return // no layer is classifying this call
+	}
+	slot.mu.Lock()
+	defer slot.mu.Unlock()
+	if slot.outcome == "" {
+		slot.outcome = o
+	}
+}
+
+// invalidArguments builds the error result for bad input and marks the call as a validation error
+// tools use it instead of mcp.NewToolResultError so dashboards can tell caller bugs from tool bugs
+func InvalidArguments(ctx context.Context, err error) *mcp.CallToolResult {
+	SetOutcome(ctx, OutcomeValidationError)
+	return mcp.NewToolResultError(err.Error())
+}
+
+// classify settles a call's outcome: whatever an inner layer recorded, otherwise
+// it's read off the return values - and then pinned, so every outer layer agrees
+func (s *outcomeSlot) classify(result *mcp.CallToolResult, err error) Outcome {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.outcome != "" {
+		return s.outcome
+	}
+
+	switch {
+	case errors.Is(err, context.Canceled):
+		s.outcome = OutcomeCancelled
+	case errors.Is(err, context.DeadlineExceeded):
+		s.outcome = OutcomeTimeout
+	case err != nil:
+		s.outcome = OutcomeInternalError
+	case result != nil && result.IsError:
+		s.outcome = OutcomeToolError
+	default:
+		s.outcome = OutcomeSuccess
+	}
+	return s.outcome
+}
+
+// isError reports whether an outcome counts against the tool in ToolErrors
+// timeouts, panics, rejections and cancellations have counters of their own, and a denial
+// is the caller's problem, not the tool's
+func (o Outcome) isError() bool {
+	return o == OutcomeValidationError || o == OutcomeToolError || o == OutcomeInternalError
+}
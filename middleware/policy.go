@@ -0,0 +1,50 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// toolAnnotations holds each tool's behaviour hints, recorded at registration time
+var toolAnnotations = struct {
+	sync.RWMutex
+	byName map[string]mcp.ToolAnnotation
+}{byName: make(map[string]mcp.ToolAnnotation)}
+
+// setToolAnnotations remembers what a tool says about itself
+func SetToolAnnotations(tool string, a mcp.ToolAnnotation) {
+	toolAnnotations.Lock()
+	defer toolAnnotations.Unlock()
+	toolAnnotations.byName[tool] = a
+}
+
+// isDestructive reads the hints the way the MCP spec does: a read-only tool can't be destructive,
+// and a tool that doesn't say otherwise is assumed to be
+func IsDestructive(a mcp.ToolAnnotation) bool {
+	if a.ReadOnlyHint != nil && *a.ReadOnlyHint {
+		return false
+	}
+	return a.DestructiveHint == nil || *a.DestructiveHint
+}
+
+// withToolPolicy refuses destructive tools unless GlobalSettings.AllowDestructiveTools says otherwise
+// a tool that can delete things should be switched on by a human, not discovered by an agent
+func WithToolPolicy(name string, handler server.ToolHandlerFunc) server.ToolHandlerFunc {
+	return func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		toolAnnotations.RLock()
+		a, ok := toolAnnotations.byName[name]
+		toolAnnotations.RUnlock()
+		if !ok || GlobalSettings.AllowDestructiveTools || !IsDestructive(a) {
+			return handler(ctx, req)
+		}
+
+		SetOutcome(ctx, OutcomeRejected)
+		slog.WarnContext(ctx, "tool call refused: destructive tools are disabled", "component", "tool", "tool", name)
+		return mcp.NewToolResultError(fmt.Sprintf("Tool %s is destructive and disabled by server policy", name)), nil
+	}
+}
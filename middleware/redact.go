@@ -0,0 +1,124 @@
+package middleware
+
+import (
+	"fmt"
+	"path"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+// redactedValue replaces anything too sensitive to write down
+const redactedValue = "[REDACTED]"
+
+// redactionPolicy decides which argument values are too sensitive (or too big) to log
+// like a court stenographer who knows which parts of the transcript get sealed
+type RedactionPolicy struct {
+	Patterns       []string // glob patterns (path.Match syntax) matched case-insensitively against argument names
+	MaxValueLength int      // strings longer than this many bytes get truncated; 0 means no limit
+}
+
+// globalRedaction applies to every tool and prompt on top of what each one declares
+// the defaults catch the usual suspects so a forgetful tool author doesn't leak a key
+var GlobalRedaction = RedactionPolicy{
+	Patterns:       []string{"*password*", "*passwd*", "*secret*", "*token*", "*api_key*", "*apikey*", "authorization", "*credential*"},
+	MaxValueLength: 1024,
+}
+
+// sensitiveArgs holds the per-capability patterns declared at registration time
+var sensitiveArgs = struct {
+	sync.RWMutex
+	tools   map[string][]string
+	prompts map[string][]string
+}{
+	tools:   make(map[string][]string),
+	prompts: make(map[string][]string),
+}
+
+// declareSensitiveToolArgs marks argument names (or glob patterns) of a tool as secret
+// their values show up as [REDACTED] in the logs from then on
+func DeclareSensitiveToolArgs(tool string, patterns ...string) {
+	sensitiveArgs.Lock()
+	defer sensitiveArgs.Unlock()
+	sensitiveArgs.tools[tool] = append(sensitiveArgs.tools[tool], patterns...)
+}
+
+// declareSensitivePromptArgs does the same for prompt arguments
+func DeclareSensitivePromptArgs(prompt string, patterns ...string) {
+	sensitiveArgs.Lock()
+	defer sensitiveArgs.Unlock()
+	sensitiveArgs.prompts[prompt] = append(sensitiveArgs.prompts[prompt], patterns...)
+}
+
+// redactToolArgs returns a log-safe copy of a tool's arguments
+// the original arguments are never modified - the handler still sees the real values
+func redactToolArgs(tool string, args any) any {
+	sensitiveArgs.RLock()
+	extra := sensitiveArgs.tools[tool]
+	sensitiveArgs.RUnlock()
+	return GlobalRedaction.redact(args, extra)
+}
+
+// redactPromptArgs returns a log-safe copy of a prompt's arguments
+func redactPromptArgs(prompt string, args map[string]string) any {
+	sensitiveArgs.RLock()
+	extra := sensitiveArgs.prompts[prompt]
+	sensitiveArgs.RUnlock()
+
+	out := make(map[string]any, len(args))
+	for k, v := range args {
+		out[k] = v
+	}
+	return GlobalRedaction.redact(out, extra)
+}
+
+// redact walks maps and lists, masking sensitive keys and truncating long strings
+func (p RedactionPolicy) redact(v any, extra []string) any {
+	switch val := v.(type) {
+	case map[string]any:
+		out := make(map[string]any, len(val))
+		for k, item := range val {
+			if p.isSensitive(k, extra) {
+				out[k] = redactedValue
+				continue
+			}
+			out[k] = p.redact(item, extra)
+		}
+		return out
+	case []any:
+		out := make([]any, len(val))
+		for i, item := range val {
+			out[i] = p.redact(item, extra)
+		}
+		return out
+	case string:
+		return p.truncate(val)
+	default:
+		return v
+	}
+}
+
+// isSensitive reports whether an argument name matches a global or declared pattern
+func (p RedactionPolicy) isSensitive(name string, extra []string) bool {
+	name = strings.ToLower(name)
+	for _, patterns := range [][]string{p.Patterns, extra} {
+		for _, pattern := range patterns {
+			if ok, _ := path.Match(strings.ToLower(pattern), name); ok {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// truncate keeps huge arguments (file contents, base64 blobs...) from flooding the log
+func (p RedactionPolicy) truncate(s string) string {
+	if p.MaxValueLength <= 0 || len(s) <= p.MaxValueLength {
+		return s
+	}
+	cut := p.MaxValueLength
+	for cut > 0 && !utf8.RuneStart(s[cut]) {
+		cut-- // don't slice a multi-byte character in half
+	}
+	return fmt.Sprintf("%s...[truncated %d bytes]", s[:cut], len(s)-cut)
+}
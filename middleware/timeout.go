@@ -0,0 +1,99 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// toolTimeouts holds the per-tool deadlines decided at registration time
+// tools that aren't listed fall back to GlobalSettings.DefaultToolTimeout
+var toolTimeouts = struct {
+	sync.RWMutex
+	byName map[string]time.Duration
+}{byName: make(map[string]time.Duration)}
+
+// setToolTimeout sets how long a tool may run before we give up on it
+// zero means "no deadline" for that tool, even if there's a global default
+func SetToolTimeout(tool string, d time.Duration) {
+	toolTimeouts.Lock()
+	defer toolTimeouts.Unlock()
+	toolTimeouts.byName[tool] = d
+}
+
+// toolTimeout looks up the effective deadline for a tool
+func toolTimeout(tool string) time.Duration {
+	toolTimeouts.RLock()
+	defer toolTimeouts.RUnlock()
+	if d, ok := toolTimeouts.byName[tool]; ok {
+		return d
+	}
+	return GlobalSettings.DefaultToolTimeout
+}
+
+// toolOutcome carries a handler's return values across the goroutine boundary
+type toolOutcome struct {
+	result *mcp.CallToolResult
+	err    error
+}
+
+// withToolTimeout cancels a tool's context at its deadline and answers the client right away
+// a well-behaved handler notices ctx.Done() and stops; a stubborn one finishes in the
+// background, but the caller is no longer stuck waiting for it - and it keeps its
+// concurrency slot until it does, so max_concurrent still counts it
+func WithToolTimeout(name string, handler server.ToolHandlerFunc) server.ToolHandlerFunc {
+	return func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		timeout := toolTimeout(name)
+		if timeout <= 0 {
+			return handler(ctx, req) // no deadline configured - run inline like before
+		}
+
+		ctx, cancel := context.WithTimeout(ctx, timeout)
+		defer cancel()
+
+		lease := slotLeaseFrom(ctx)
+		done := make(chan toolOutcome, 1) // buffered so an abandoned handler can still exit
+		go func() {
+			result, err := handler(ctx, req)
+			if lease != nil {
+				lease.release() // the handler is really done now, answered or not
+			}
+			done <- toolOutcome{result, err}
+		}()
+
+		select {
+		case out := <-done:
+			// a cooperative handler may return ctx.Err() right at the deadline - that's still a timeout
+			if out.err != nil && errors.Is(ctx.Err(), context.DeadlineExceeded) {
+				return timedOut(ctx, name, timeout), nil
+			}
+			return out.result, out.err
+		case <-ctx.Done():
+			if lease != nil {
+				lease.handedOff.Store(true) // still running - the goroutine gives the slot back
+			}
+			if !errors.Is(ctx.Err(), context.DeadlineExceeded) {
+				SetOutcome(ctx, OutcomeCancelled)
+				return nil, ctx.Err() // the client cancelled - not our timeout to report
+			}
+			return timedOut(ctx, name, timeout), nil
+		}
+	}
+}
+
+// timedOut records the timeout and builds the error the client sees
+func timedOut(ctx context.Context, name string, timeout time.Duration) *mcp.CallToolResult {
+	SetOutcome(ctx, OutcomeTimeout)
+	GlobalMetrics.mu.Lock()
+	GlobalMetrics.ToolTimeouts[name]++
+	GlobalMetrics.mu.Unlock()
+
+	slog.WarnContext(ctx, "tool call timed out", "component", "tool", "tool", name, "timeout", timeout.String())
+	return mcp.NewToolResultError(fmt.Sprintf("Tool %s timed out after %v", name, timeout))
+}
@@ -0,0 +1,209 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"slices"
+	"sync"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// tracerName identifies our spans in whatever backend ends up receiving them
+const tracerName = "github.com/suramrit/hello-mcp/middleware"
+
+// withToolTracing opens a span around every tool call
+// if the client sent a traceparent in _meta, our span joins its trace instead of starting a new one
+func WithToolTracing(name string, handler server.ToolHandlerFunc) server.ToolHandlerFunc {
+	return func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		ctx = extractTraceContext(ctx, req.Params.Meta, req.Header)
+		ctx, slot := ensureOutcome(ctx)
+		ctx, span := otel.Tracer(tracerName).Start(ctx, "tools/call "+name,
+			trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(
+				attribute.String("mcp.kind", "tool"),
+				attribute.String("mcp.tool.name", name),
+				attribute.StringSlice("mcp.arguments", argumentKeys(req.GetArguments())),
+			))
+		defer span.End()
+
+		result, err := handler(ctx, req)
+		span.SetAttributes(attribute.String("mcp.outcome", string(slot.classify(result, err))))
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+			return result, err
+		}
+		if result != nil {
+			span.SetAttributes(
+				attribute.Bool("mcp.result.is_error", result.IsError),
+				attribute.Int("mcp.result.size", resultSize(span, result)),
+			)
+			if result.IsError {
+				span.SetStatus(codes.Error, "tool returned an error result")
+			}
+		}
+		return result, nil
+	}
+}
+
+// withResourceTracing opens a span around every resource read
+func WithResourceTracing(uri string, handler server.ResourceHandlerFunc) server.ResourceHandlerFunc {
+	return func(ctx context.Context, req mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+		ctx = extractTraceContext(ctx, req.Request.Params.Meta, req.Header)
+		ctx, span := otel.Tracer(tracerName).Start(ctx, "resources/read "+uri,
+			trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(
+				attribute.String("mcp.kind", "resource"),
+				attribute.String("mcp.resource.uri", uri),
+			))
+		defer span.End()
+
+		contents, err := handler(ctx, req)
+		span.SetAttributes(attribute.Bool("mcp.result.is_error", err != nil))
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+			return contents, err
+		}
+		span.SetAttributes(
+			attribute.Int("mcp.result.items", len(contents)),
+			attribute.Int("mcp.result.size", resultSize(span, contents)),
+		)
+		return contents, nil
+	}
+}
+
+// withPromptTracing opens a span around every prompt get
+func WithPromptTracing(name string, handler server.PromptHandlerFunc) server.PromptHandlerFunc {
+	return func(ctx context.Context, req mcp.GetPromptRequest) (*mcp.GetPromptResult, error) {
+		ctx = extractTraceContext(ctx, req.Request.Params.Meta, req.Header)
+		ctx, span := otel.Tracer(tracerName).Start(ctx, "prompts/get "+name,
+			trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(
+				attribute.String("mcp.kind", "prompt"),
+				attribute.String("mcp.prompt.name", name),
+				attribute.StringSlice("mcp.arguments", argumentKeys(req.Params.Arguments)),
+			))
+		defer span.End()
+
+		result, err := handler(ctx, req)
+		span.SetAttributes(attribute.Bool("mcp.result.is_error", err != nil))
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+			return result, err
+		}
+		span.SetAttributes(attribute.Int("mcp.result.size", resultSize(span, result)))
+		return result, nil
+	}
+}
+
+// extractTraceContext pulls W3C traceparent/tracestate out of a request's _meta,
+// falling back to the HTTP headers on network transports
+// (the SDK only decodes _meta for tool calls - AddTraceHooks fills it in for resources and prompts)
+// clients that don't trace simply don't send them, and we start a fresh trace
+func extractTraceContext(ctx context.Context, meta *mcp.Meta, header http.Header) context.Context {
+	propagator := propagation.TraceContext{}
+	if meta != nil && len(meta.AdditionalFields) > 0 {
+		carrier := propagation.MapCarrier{}
+		for k, v := range meta.AdditionalFields {
+			if s, ok := v.(string); ok {
+				carrier[k] = s
+			}
+		}
+		if sc := trace.SpanContextFromContext(propagator.Extract(ctx, carrier)); sc.IsValid() {
+			return trace.ContextWithRemoteSpanContext(ctx, sc)
+		}
+	}
+	if header != nil {
+		return propagator.Extract(ctx, propagation.HeaderCarrier(header))
+	}
+	return ctx
+}
+
+// pendingMeta holds the _meta of resource reads and prompt gets between the two hooks that see them
+// the SDK hands both hooks the very same ctx, and a fresh one per message, so it makes an exact key
+var pendingMeta = struct {
+	sync.Mutex
+	byCtx map[context.Context]*mcp.Meta
+}{byCtx: make(map[context.Context]*mcp.Meta)}
+
+// addTraceHooks makes _meta reach the resource and prompt tracing layers
+// the SDK decodes params into a field that shadows Request.Params.Meta, so it's never set -
+// instead we read _meta from the raw message and put it back before the handler runs,
+// which joins the client's trace on every transport, stdio included
+func AddTraceHooks(hooks *server.Hooks) {
+	hooks.AddOnRequestInitialization(func(ctx context.Context, id any, message any) error {
+		raw, ok := message.(json.RawMessage)
+		if !ok || !bytes.Contains(raw, []byte(`"_meta"`)) {
+			return nil // cheap check first - this runs on every request
+		}
+		var req struct {
+			Method mcp.MCPMethod `json:"method"`
+			Params struct {
+				Meta *mcp.Meta `json:"_meta"`
+			} `json:"params"`
+		}
+		if json.Unmarshal(raw, &req) != nil || req.Params.Meta == nil {
+			return nil
+		}
+		if req.Method != mcp.MethodResourcesRead && req.Method != mcp.MethodPromptsGet {
+			return nil // tool calls get their _meta from the SDK
+		}
+		pendingMeta.Lock()
+		defer pendingMeta.Unlock()
+		pendingMeta.byCtx[ctx] = req.Params.Meta
+		return nil
+	})
+	hooks.AddBeforeReadResource(func(ctx context.Context, id any, req *mcp.ReadResourceRequest) {
+		req.Request.Params.Meta = takeMeta(ctx)
+	})
+	hooks.AddBeforeGetPrompt(func(ctx context.Context, id any, req *mcp.GetPromptRequest) {
+		req.Request.Params.Meta = takeMeta(ctx)
+	})
+	// a request the SDK can't parse never reaches its Before hook; don't keep its _meta forever
+	hooks.AddOnError(func(ctx context.Context, id any, method mcp.MCPMethod, message any, err error) {
+		takeMeta(ctx)
+	})
+}
+
+// takeMeta removes and returns what the request hook stashed for ctx, if anything
+func takeMeta(ctx context.Context) *mcp.Meta {
+	pendingMeta.Lock()
+	defer pendingMeta.Unlock()
+	meta := pendingMeta.byCtx[ctx]
+	delete(pendingMeta.byCtx, ctx)
+	return meta
+}
+
+// argumentKeys lists argument names only - values may be secrets, and spans leave the building
+func argumentKeys[V any](args map[string]V) []string {
+	keys := make([]string, 0, len(args))
+	for k := range args {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+	return keys
+}
+
+// resultSize measures a result as the bytes it takes on the wire
+// marshalling isn't free, so we skip it when nobody is recording the span
+func resultSize(span trace.Span, v any) int {
+	if !span.IsRecording() {
+		return 0
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return 0
+	}
+	return len(b)
+}
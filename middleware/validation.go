@@ -0,0 +1,171 @@
+package middleware
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/suramrit/hello-mcp/internal/schema"
+)
+
+// toolSchemas holds each tool's input schema, decoded once at registration time
+var toolSchemas = struct {
+	sync.RWMutex
+	byName map[string]map[string]any
+}{byName: make(map[string]map[string]any)}
+
+// setToolInputSchema remembers a tool's input schema so calls can be checked against it
+// it reads whichever of InputSchema or RawInputSchema the tool definition uses
+func SetToolInputSchema(tool mcp.Tool) error {
+	var src any = tool.InputSchema
+	if tool.RawInputSchema != nil {
+		src = tool.RawInputSchema
+	}
+	s, err := schema.FromJSON(src)
+	if err != nil {
+		return fmt.Errorf("tool %s: input schema: %w", tool.Name, err)
+	}
+
+	toolSchemas.Lock()
+	defer toolSchemas.Unlock()
+	toolSchemas.byName[tool.Name] = s
+	return nil
+}
+
+// invalidArguments is the structured half of a validation failure
+// agents can fix their call from this without parsing our prose
+type invalidArguments struct {
+	Error    string           `json:"error"` // always "invalid_arguments"
+	Problems []schema.Problem `json:"problems"`
+}
+
+// withToolValidation checks a call's arguments against the tool's input schema before the handler runs
+// every problem is reported at once, so the caller fixes its call in one round trip instead of five
+func WithToolValidation(name string, handler server.ToolHandlerFunc) server.ToolHandlerFunc {
+	return func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		toolSchemas.RLock()
+		s := toolSchemas.byName[name]
+		toolSchemas.RUnlock()
+		if s == nil {
+			return handler(ctx, req) // nothing declared, nothing to check
+		}
+
+		// a missing arguments object is the same as an empty one - "required" still applies
+		args := req.Params.Arguments
+		if args == nil {
+			args = map[string]any{}
+		}
+		problems := schema.Validate(s, args)
+		if len(problems) == 0 {
+			return handler(ctx, req)
+		}
+
+		SetOutcome(ctx, OutcomeValidationError)
+		lines := make([]string, len(problems))
+		for i, p := range problems {
+			lines[i] = "- " + p.String()
+		}
+		slog.WarnContext(ctx, "tool call rejected: invalid arguments", "component", "tool", "tool", name, "problems", len(problems))
+
+		result := mcp.NewToolResultError(fmt.Sprintf("Invalid arguments for tool %s:\n%s", name, strings.Join(lines, "\n")))
+		result.StructuredContent = invalidArguments{Error: "invalid_arguments", Problems: problems}
+		return result, nil
+	}
+}
+
+// output validation modes - off in production, where a slightly-off result beats no result
+const (
+	OutputValidationOff    = "off"    // trust the tools
+	OutputValidationWarn   = "warn"   // log results that don't match their schema, but deliver them
+	OutputValidationStrict = "strict" // replace them with an error, so a bad tool fails loudly in dev and CI
+)
+
+// toolOutputSchemas holds the output schema of every tool that declares one
+var toolOutputSchemas = struct {
+	sync.RWMutex
+	byName map[string]map[string]any
+}{byName: make(map[string]map[string]any)}
+
+// setToolOutputSchema remembers a tool's output schema, if it declares one
+func SetToolOutputSchema(tool mcp.Tool) error {
+	var src any
+	switch {
+	case tool.RawOutputSchema != nil:
+		src = tool.RawOutputSchema
+	case tool.OutputSchema.Type != "":
+		src = tool.OutputSchema
+	default:
+		return nil // plain-text tool - nothing to hold it to
+	}
+	s, err := schema.FromJSON(src)
+	if err != nil {
+		return fmt.Errorf("tool %s: output schema: %w", tool.Name, err)
+	}
+
+	toolOutputSchemas.Lock()
+	defer toolOutputSchemas.Unlock()
+	toolOutputSchemas.byName[tool.Name] = s
+	return nil
+}
+
+// withToolOutputValidation checks successful results against the tool's declared output schema
+// what happens on a mismatch depends on GlobalSettings.OutputValidation
+func WithToolOutputValidation(name string, handler server.ToolHandlerFunc) server.ToolHandlerFunc {
+	return func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		result, err := handler(ctx, req)
+		if err != nil || result == nil || result.IsError {
+			return result, err // errors don't promise any particular shape
+		}
+
+		toolOutputSchemas.RLock()
+		s := toolOutputSchemas.byName[name]
+		toolOutputSchemas.RUnlock()
+		if s == nil {
+			return result, nil
+		}
+
+		problems := checkOutput(s, result)
+		if len(problems) == 0 {
+			return result, nil
+		}
+
+		lines := make([]string, len(problems))
+		for i, p := range problems {
+			lines[i] = "- " + p.String()
+		}
+		slog.WarnContext(ctx, "tool result does not match its output schema", "component", "tool", "tool", name, "problems", strings.Join(lines, "; "))
+		if GlobalSettings.OutputValidation != OutputValidationStrict {
+			return result, nil
+		}
+		SetOutcome(ctx, OutcomeInternalError) // the tool broke its own contract - that's on us, not the caller
+		return mcp.NewToolResultError(fmt.Sprintf("Tool %s returned a result that does not match its output schema:\n%s", name, strings.Join(lines, "\n"))), nil
+	}
+}
+
+// checkOutput validates the structured content and insists on a text fallback next to it
+func checkOutput(s map[string]any, result *mcp.CallToolResult) []schema.Problem {
+	if result.StructuredContent == nil {
+		return []schema.Problem{{Message: "structured content is missing"}}
+	}
+
+	var problems []schema.Problem
+	if len(result.Content) == 0 {
+		problems = append(problems, schema.Problem{Message: "text fallback is missing - older clients would see an empty result"})
+	}
+
+	// round-trip through JSON so Go structs are checked exactly as the client will see them
+	b, err := json.Marshal(result.StructuredContent)
+	if err != nil {
+		return append(problems, schema.Problem{Message: fmt.Sprintf("structured content cannot be encoded: %v", err)})
+	}
+	var value any
+	if err := json.Unmarshal(b, &value); err != nil {
+		return append(problems, schema.Problem{Message: fmt.Sprintf("structured content cannot be decoded: %v", err)})
+	}
+	return append(problems, schema.Validate(s, value)...)
+}
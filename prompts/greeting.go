@@ -6,6 +6,7 @@ import (
 
 	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/mark3labs/mcp-go/server"
+	"github.com/suramrit/hello-mcp/internal/registry"
 )
 
 // greetingPrompt provides AI with a friendly conversation template
@@ -14,6 +15,11 @@ type GreetingPrompt struct {
 	// no state needed - we're just a template generator
 }
 
+// greeting registers itself - politeness should be automatic
+func init() {
+	Register(NewGreetingPrompt(), registry.WithTags("demo"))
+}
+
 // newGreetingPrompt creates our conversation-starting prompt
 // every good AI assistant needs to know how to be polite!
 func NewGreetingPrompt() *GreetingPrompt {
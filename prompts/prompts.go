@@ -11,3 +11,9 @@ type Prompt interface {
 	GetPrompt() mcp.Prompt                // what kind of prompt are you? (name, description, arguments)
 	GetHandler() server.PromptHandlerFunc // how do we generate you? (the template creation logic)
 }
+
+// sensitivePrompt is an optional extra for prompts whose arguments shouldn't be logged
+// same rules as tools.SensitiveTool: names or glob patterns, masked in the logs
+type SensitivePrompt interface {
+	SensitiveArguments() []string
+}
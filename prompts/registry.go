@@ -0,0 +1,19 @@
+package prompts
+
+import (
+	"github.com/suramrit/hello-mcp/internal/registry"
+)
+
+// promptRegistry holds every prompt that has registered itself
+var promptRegistry = registry.New[Prompt]("prompt")
+
+// register adds a prompt to the registry under its declared name
+// call it from init() in the prompt's own file
+func Register(prompt Prompt, opts ...registry.Option) {
+	promptRegistry.Add(prompt.GetPrompt().Name, prompt, opts...)
+}
+
+// registered returns all registered prompts sorted by name
+func Registered() ([]registry.Entry[Prompt], error) {
+	return promptRegistry.Entries()
+}
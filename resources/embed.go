@@ -0,0 +1,86 @@
+package resources
+
+import (
+	"embed"
+	"errors"
+	"io/fs"
+	"slices"
+	"strings"
+)
+
+// bundled is resources/static, compiled into the binary so the server finds its files from any working directory
+//
+//go:embed static
+var bundled embed.FS
+
+// bundled returns the files compiled into the binary, rooted at the static directory
+func Bundled() fs.FS {
+	sub, err := fs.Sub(bundled, "static")
+	if err != nil {
+		panic(err) // "static" is a valid path by construction
+	}
+	return sub
+}
+
+// overlayFS looks a file up in each layer in turn - the first one that has it wins
+// directories are merged, so an override directory can both replace bundled files and add new ones
+type overlayFS []fs.FS
+
+// overlay stacks file systems, highest precedence first; nil layers are skipped
+func Overlay(layers ...fs.FS) fs.FS {
+	return overlayFS(slices.DeleteFunc(layers, func(l fs.FS) bool { return l == nil }))
+}
+
+func (o overlayFS) Open(name string) (fs.File, error) {
+	for _, l := range o {
+		f, err := l.Open(name)
+		if err == nil {
+			return f, nil
+		}
+		if !errors.Is(err, fs.ErrNotExist) {
+			return nil, err // a broken override shouldn't quietly fall back to the bundled copy
+		}
+	}
+	return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
+}
+
+func (o overlayFS) Stat(name string) (fs.FileInfo, error) {
+	for _, l := range o {
+		info, err := fs.Stat(l, name)
+		if err == nil {
+			return info, nil
+		}
+		if !errors.Is(err, fs.ErrNotExist) {
+			return nil, err
+		}
+	}
+	return nil, &fs.PathError{Op: "stat", Path: name, Err: fs.ErrNotExist}
+}
+
+// readDir merges a directory across layers; where names clash, the higher layer's entry is kept
+func (o overlayFS) ReadDir(name string) ([]fs.DirEntry, error) {
+	seen := make(map[string]bool)
+	var out []fs.DirEntry
+	found := false
+	for _, l := range o {
+		entries, err := fs.ReadDir(l, name)
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				continue
+			}
+			return nil, err
+		}
+		found = true
+		for _, e := range entries {
+			if !seen[e.Name()] {
+				seen[e.Name()] = true
+				out = append(out, e)
+			}
+		}
+	}
+	if !found {
+		return nil, &fs.PathError{Op: "readdir", Path: name, Err: fs.ErrNotExist}
+	}
+	slices.SortFunc(out, func(a, b fs.DirEntry) int { return strings.Compare(a.Name(), b.Name()) })
+	return out, nil
+}
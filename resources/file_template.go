@@ -0,0 +1,133 @@
+package resources
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// fileURITemplate is how clients name a file below the root; "+" lets the path keep its slashes
+const fileURITemplate = "file:///{+path}"
+
+// fileTemplate serves any file below a root directory as file:///<path below root>
+// the root is a sandbox: "..", absolute paths and symlinks pointing outside it are all refused
+type FileTemplate struct {
+	dir  string   // the root as configured, for messages
+	root *os.Root // every open goes through this, so the OS enforces the sandbox too
+}
+
+// newFileTemplate opens root as the sandbox; the directory must exist
+func NewFileTemplate(root string) (*FileTemplate, error) {
+	r, err := os.OpenRoot(root)
+	if err != nil {
+		return nil, fmt.Errorf("file root: %w", err)
+	}
+	return &FileTemplate{dir: root, root: r}, nil
+}
+
+// getTemplate tells clients which URIs we answer
+func (t *FileTemplate) GetTemplate() mcp.ResourceTemplate {
+	return mcp.NewResourceTemplate(fileURITemplate, "Files",
+		mcp.WithTemplateDescription(fmt.Sprintf("Any file below %s, by its slash-separated path", t.dir)))
+}
+
+// getHandler reads the file the URI names, if it really is inside the root
+func (t *FileTemplate) GetHandler() server.ResourceTemplateHandlerFunc {
+	return func(ctx context.Context, req mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+		p, ok := URIVar(req, "path")
+		if !ok {
+			return nil, errors.New("missing file path")
+		}
+		name, err := sandboxPath(p)
+		if err != nil {
+			return nil, err
+		}
+
+		b, err := t.read(name)
+		if err != nil {
+			return nil, err
+		}
+		head := b[:min(len(b), sniffLen)]
+		mimeType := detectMIMEType(name, head)
+		return []mcp.ResourceContents{fileContents(req.Params.URI, mimeType, isText(mimeType, head), b)}, nil
+	}
+}
+
+// stat finds the file a URI names, through the sandbox, so subscribers hear when it changes
+func (t *FileTemplate) Stat(uri string) (fs.FileInfo, error) {
+	escaped, ok := strings.CutPrefix(uri, "file:///")
+	if !ok {
+		return nil, fmt.Errorf("%s is not a file:/// URI", uri)
+	}
+	p, err := url.PathUnescape(escaped)
+	if err != nil {
+		return nil, fmt.Errorf("invalid file URI %s: %w", uri, err)
+	}
+	name, err := sandboxPath(p)
+	if err != nil {
+		return nil, err
+	}
+	return t.root.Stat(filepath.FromSlash(name))
+}
+
+// read opens name through the root; os.Root fails any lookup that would leave it, symlinks included
+func (t *FileTemplate) read(name string) ([]byte, error) {
+	// look before opening: opening a FIFO blocks until something writes to it, and a device
+	// could be worse - only regular files are ever opened
+	if info, err := t.root.Stat(filepath.FromSlash(name)); err != nil {
+		return nil, fmt.Errorf("file %s: %w", name, unwrapPathError(err))
+	} else if !info.Mode().IsRegular() {
+		return nil, fmt.Errorf("file %s: not a regular file", name)
+	}
+
+	f, err := t.root.Open(filepath.FromSlash(name))
+	if err != nil {
+		return nil, fmt.Errorf("file %s: %w", name, unwrapPathError(err))
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+	if !info.Mode().IsRegular() {
+		return nil, fmt.Errorf("file %s: not a regular file", name) // swapped after the first look
+	}
+	return io.ReadAll(f)
+}
+
+// sandboxPath checks a client-supplied path before it gets near the filesystem
+// os.Root would refuse these anyway, but a clear error beats "path escapes from parent"
+func sandboxPath(p string) (string, error) {
+	if p == "" || strings.ContainsRune(p, 0) || strings.Contains(p, `\`) {
+		return "", fmt.Errorf("invalid file path %q", p)
+	}
+	if path.IsAbs(p) {
+		return "", fmt.Errorf("file path %q must be relative to the file root", p)
+	}
+	for _, seg := range strings.Split(p, "/") {
+		if seg == ".." {
+			return "", fmt.Errorf("file path %q must not contain \"..\"", p)
+		}
+	}
+	return path.Clean(p), nil
+}
+
+// unwrapPathError drops the *PathError wrapper, whose message would repeat the path
+func unwrapPathError(err error) error {
+	var pe *os.PathError
+	if errors.As(err, &pe) {
+		return pe.Err
+	}
+	return err
+}
@@ -0,0 +1,228 @@
+//go:build unix
+
+package resources
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+func TestSandboxPath(t *testing.T) {
+	tests := []struct {
+		in, want, wantErr string
+	}{
+		{"notes.txt", "notes.txt", ""},
+		{"a/b/c.md", "a/b/c.md", ""},
+		{"a/./b", "a/b", ""},
+		{"a//b", "a/b", ""},
+		{"..foo/bar..", "..foo/bar..", ""}, // dots inside a name are just a name
+		{"", "", "invalid file path"},
+		{"..", "", `must not contain ".."`},
+		{"../etc/passwd", "", `must not contain ".."`},
+		{"a/../../etc/passwd", "", `must not contain ".."`},
+		{"a/..", "", `must not contain ".."`},
+		{"/etc/passwd", "", "must be relative"},
+		{`..\windows`, "", "invalid file path"},
+		{`a\b`, "", "invalid file path"},
+		{"a\x00b", "", "invalid file path"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, err := sandboxPath(tt.in)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("sandboxPath(%q) = %q, %v; want error mentioning %q", tt.in, got, err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil || got != tt.want {
+				t.Errorf("sandboxPath(%q) = %q, %v; want %q", tt.in, got, err, tt.want)
+			}
+		})
+	}
+}
+
+// fileTree builds a root with a few files, plus a secret next to it that nothing may reach
+func fileTree(t *testing.T) (root, outside string) {
+	t.Helper()
+	dir := t.TempDir()
+	root = filepath.Join(dir, "root")
+	outside = filepath.Join(dir, "secret.txt")
+
+	mustWrite := func(path, body string) {
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(body), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	mustWrite(outside, "top secret")
+	mustWrite(filepath.Join(root, "hello.txt"), "hello")
+	mustWrite(filepath.Join(root, "docs", "guide.md"), "# Guide")
+	mustWrite(filepath.Join(root, "image.png"), "\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR")
+
+	symlink := func(target, name string) {
+		if err := os.Symlink(target, filepath.Join(root, name)); err != nil {
+			t.Skipf("symlinks unavailable: %v", err)
+		}
+	}
+	symlink("../secret.txt", "escape.txt")   // relative, pointing out
+	symlink(outside, "absolute.txt")         // absolute, pointing out
+	symlink(filepath.Dir(outside), "parent") // a directory outside the root
+	symlink("docs/guide.md", "inside.md")    // stays inside - fine
+	if err := syscall.Mkfifo(filepath.Join(root, "pipe"), 0o600); err != nil {
+		t.Logf("no FIFO support, skipping that case: %v", err)
+	}
+	return root, outside
+}
+
+// readThroughServer reads uri the way a client would, so the SDK's template matching and
+// percent-decoding happen exactly as in production
+func readThroughServer(t *testing.T, srv *server.MCPServer, uri string) (*mcp.ReadResourceResult, string) {
+	t.Helper()
+	msg, _ := json.Marshal(map[string]any{
+		"jsonrpc": "2.0", "id": 1, "method": "resources/read",
+		"params": map[string]any{"uri": uri},
+	})
+	switch resp := srv.HandleMessage(context.Background(), msg).(type) {
+	case mcp.JSONRPCResponse:
+		result, ok := resp.Result.(mcp.ReadResourceResult)
+		if !ok {
+			t.Fatalf("unexpected result %T", resp.Result)
+		}
+		return &result, ""
+	case mcp.JSONRPCError:
+		return nil, resp.Error.Message
+	default:
+		t.Fatalf("unexpected response %T", resp)
+	}
+	return nil, ""
+}
+
+func TestFileTemplateReads(t *testing.T) {
+	root, _ := fileTree(t)
+	ft, err := NewFileTemplate(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := server.NewMCPServer("test", "0", server.WithResourceCapabilities(false, false))
+	srv.AddResourceTemplate(ft.GetTemplate(), ft.GetHandler())
+
+	tests := []struct {
+		name, uri string
+		wantText  string // for successful text reads
+		wantBlob  bool   // for successful binary reads
+		wantErr   string // substring of the error; empty means the read succeeds
+	}{
+		{"plain file", "file:///hello.txt", "hello", false, ""},
+		{"nested file", "file:///docs/guide.md", "# Guide", false, ""},
+		{"symlink inside the root", "file:///inside.md", "# Guide", false, ""},
+		{"binary file", "file:///image.png", "", true, ""},
+		{"missing file", "file:///nope.txt", "", false, "no such file"},
+		{"dot-dot", "file:///../secret.txt", "", false, `must not contain ".."`},
+		{"dot-dot in the middle", "file:///docs/../../secret.txt", "", false, `must not contain ".."`},
+		{"encoded dot-dot", "file:///%2e%2e/secret.txt", "", false, `must not contain ".."`},
+		{"encoded slash and dot-dot", "file:///docs%2f..%2f..%2fsecret.txt", "", false, `must not contain ".."`},
+		{"absolute path", "file:////etc/passwd", "", false, "must be relative"},
+		{"encoded absolute path", "file:///%2fetc%2fpasswd", "", false, "must be relative"},
+		{"backslash", `file:///..%5csecret.txt`, "", false, "invalid file path"},
+		{"NUL", "file:///hello.txt%00.png", "", false, "invalid file path"},
+		{"relative symlink out of the root", "file:///escape.txt", "", false, "escapes"},
+		{"absolute symlink out of the root", "file:///absolute.txt", "", false, "escapes"},
+		{"through a symlinked directory", "file:///parent/secret.txt", "", false, "escapes"},
+		{"directory", "file:///docs", "", false, "not a regular file"},
+	}
+	if _, err := os.Stat(filepath.Join(root, "pipe")); err == nil {
+		tests = append(tests, struct {
+			name, uri string
+			wantText  string
+			wantBlob  bool
+			wantErr   string
+		}{"FIFO", "file:///pipe", "", false, "not a regular file"})
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, errMsg := readThroughServer(t, srv, tt.uri)
+			if tt.wantErr != "" {
+				if errMsg == "" {
+					t.Fatalf("read %s succeeded: %+v", tt.uri, result.Contents)
+				}
+				if !strings.Contains(errMsg, tt.wantErr) {
+					t.Errorf("read %s: error %q, want it to mention %q", tt.uri, errMsg, tt.wantErr)
+				}
+				if strings.Contains(errMsg, "top secret") {
+					t.Errorf("read %s: error leaks the file: %q", tt.uri, errMsg)
+				}
+				return
+			}
+			if errMsg != "" {
+				t.Fatalf("read %s: %s", tt.uri, errMsg)
+			}
+			if len(result.Contents) != 1 {
+				t.Fatalf("read %s: %d items", tt.uri, len(result.Contents))
+			}
+			switch c := result.Contents[0].(type) {
+			case mcp.TextResourceContents:
+				if tt.wantBlob || c.Text != tt.wantText || c.URI != tt.uri {
+					t.Errorf("read %s: text contents %+v", tt.uri, c)
+				}
+			case mcp.BlobResourceContents:
+				if !tt.wantBlob || c.URI != tt.uri || c.MIMEType != "image/png" {
+					t.Errorf("read %s: blob contents uri=%s mime=%s", tt.uri, c.URI, c.MIMEType)
+				}
+			default:
+				t.Errorf("read %s: unexpected contents %T", tt.uri, c)
+			}
+		})
+	}
+}
+
+func TestFileTemplateStat(t *testing.T) {
+	root, _ := fileTree(t)
+	ft, err := NewFileTemplate(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if info, err := ft.Stat("file:///docs/guide.md"); err != nil || info.Size() != int64(len("# Guide")) {
+		t.Errorf("Stat(guide) = %v, %v", info, err)
+	}
+	if _, err := ft.Stat("file:///nope.txt"); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("Stat(missing) = %v, want ErrNotExist", err)
+	}
+	for _, uri := range []string{
+		"file:///../secret.txt",
+		"file:///%2e%2e/secret.txt",
+		"file:////etc/passwd",
+		"file:///escape.txt",
+		"file:///parent/secret.txt",
+		"static://hello.txt",
+		"file:///%zz",
+	} {
+		info, err := ft.Stat(uri)
+		if err == nil {
+			t.Errorf("Stat(%s) = %v, want an error", uri, info.Name())
+		} else if errors.Is(err, fs.ErrNotExist) {
+			// the subscription manager treats ErrNotExist as "watchable, just not there yet"
+			t.Errorf("Stat(%s) = %v; an escape must not look like a missing file", uri, err)
+		}
+	}
+}
+
+func TestNewFileTemplateMissingRoot(t *testing.T) {
+	if _, err := NewFileTemplate(filepath.Join(t.TempDir(), "nope")); err == nil {
+		t.Error("NewFileTemplate accepted a root that doesn't exist")
+	}
+}
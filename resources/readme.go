@@ -2,18 +2,54 @@ package resources
 
 import (
 	"context"
+	"io/fs"
 	"os"
+	"sync"
 
 	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/mark3labs/mcp-go/server"
+	"github.com/suramrit/hello-mcp/internal/registry"
 )
 
+// readmePath is where the README lives in readmeFiles
+const readmePath = "README.md"
+
+// readmeFiles holds the README - its own tree, not the static one, so the static
+// scan doesn't serve the same file a second time as static://README.md
+// it starts as the working directory; main points it at the copy compiled into the binary
+var readmeFiles = struct {
+	sync.RWMutex
+	fsys fs.FS
+}{fsys: os.DirFS(".")}
+
+// setReadmeFiles says where the README resource reads README.md from
+func SetReadmeFiles(fsys fs.FS) {
+	readmeFiles.Lock()
+	defer readmeFiles.Unlock()
+	readmeFiles.fsys = fsys
+}
+
+func readmeFS() fs.FS {
+	readmeFiles.RLock()
+	defer readmeFiles.RUnlock()
+	return readmeFiles.fsys
+}
+
+// readmeURI is what clients and policy files know the README by; the declaration and the
+// contents both use it, so they can't drift apart
+const readmeURI = "file://README.md"
+
 // readmeResource gives AI access to our README file
 // think of this as our helpful librarian that fetches books on demand
 type ReadmeResource struct {
 	// no state needed - we're just a simple file reader
 }
 
+// the README signs itself up for the library on startup
+func init() {
+	Register(NewReadmeResource(), registry.WithTags("docs"))
+}
+
 // newReadmeResource creates our file-reading resource
 // every project needs documentation, and every AI needs access to it!
 func NewReadmeResource() *ReadmeResource {
@@ -24,18 +60,17 @@ func NewReadmeResource() *ReadmeResource {
 // this is like putting a label on a library book
 func (r *ReadmeResource) GetResource() mcp.Resource {
 	return mcp.NewResource(
-		"file://README.md",             // unique URI - like a library catalog number
-		"Local README",                 // human-readable description
-		mcp.WithMIMEType("text/plain")) // tell clients what kind of content this is
+		readmeURI,                         // unique URI - like a library catalog number
+		"Local README",                    // human-readable description
+		mcp.WithMIMEType("text/markdown")) // tell clients what kind of content this is
 }
 
 // getHandler returns the function that actually reads the file
 // this is our librarian in action - fetching the requested book
 func (r *ReadmeResource) GetHandler() server.ResourceHandlerFunc {
 	return func(ctx context.Context, req mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
-		// try to read the file from our resources directory
-		// note: this path is relative to where the server runs
-		b, err := os.ReadFile("resources/static/README.md")
+		// read the copy compiled into the binary, so it doesn't matter where the server was started from
+		b, err := fs.ReadFile(readmeFS(), readmePath)
 		if err != nil {
 			// if file doesn't exist or can't be read, let the caller know
 			return nil, err
@@ -44,10 +79,15 @@ func (r *ReadmeResource) GetHandler() server.ResourceHandlerFunc {
 		// package up the file contents in the format MCP expects
 		return []mcp.ResourceContents{
 			mcp.TextResourceContents{
-				URI:      "docs://readme", // internal URI for this content
+				URI:      readmeURI,       // the same catalog number we declared
 				MIMEType: "text/markdown", // what kind of content this actually is
 				Text:     string(b),       // the actual file contents
 			},
 		}, nil
 	}
 }
+
+// stat lets subscribers hear when the README changes
+func (r *ReadmeResource) Stat(uri string) (fs.FileInfo, error) {
+	return fs.Stat(readmeFS(), readmePath)
+}
@@ -0,0 +1,20 @@
+package resources
+
+import (
+	"github.com/suramrit/hello-mcp/internal/registry"
+)
+
+// resourceRegistry holds every resource that has registered itself, keyed by URI
+// two resources can't share a catalog number
+var resourceRegistry = registry.New[Resource]("resource")
+
+// register adds a resource to the registry under its URI
+// call it from init() in the resource's own file
+func Register(resource Resource, opts ...registry.Option) {
+	resourceRegistry.Add(resource.GetResource().URI, resource, opts...)
+}
+
+// registered returns all registered resources sorted by URI
+func Registered() ([]registry.Entry[Resource], error) {
+	return resourceRegistry.Entries()
+}
@@ -0,0 +1,57 @@
+package resources
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/suramrit/hello-mcp/internal/registry"
+	"github.com/suramrit/hello-mcp/status"
+)
+
+// serverMetricsURI is where clients find the health report
+const serverMetricsURI = "metrics://server"
+
+// the metrics report is just another book on the shelf
+func init() {
+	Register(NewServerMetricsResource(), registry.WithTags("ops"))
+}
+
+// serverMetricsResource exposes the server's health report as a readable resource
+// same data as the server_stats tool, for clients that prefer to browse rather than call
+type ServerMetricsResource struct {
+	// no state needed - the status package keeps the books
+}
+
+// newServerMetricsResource creates the health report resource
+func NewServerMetricsResource() *ServerMetricsResource {
+	return &ServerMetricsResource{}
+}
+
+// getResource labels the report as JSON
+func (r *ServerMetricsResource) GetResource() mcp.Resource {
+	return mcp.NewResource(
+		serverMetricsURI,
+		"Server metrics and health",
+		mcp.WithResourceDescription("Uptime, version, registered capabilities, call metrics and Go runtime stats"),
+		mcp.WithMIMEType("application/json"))
+}
+
+// getHandler renders a fresh snapshot on every read
+func (r *ServerMetricsResource) GetHandler() server.ResourceHandlerFunc {
+	return func(ctx context.Context, req mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+		b, err := json.MarshalIndent(status.Snapshot(), "", "  ")
+		if err != nil {
+			return nil, err
+		}
+
+		return []mcp.ResourceContents{
+			mcp.TextResourceContents{
+				URI:      serverMetricsURI,
+				MIMEType: "application/json",
+				Text:     string(b),
+			},
+		}, nil
+	}
+}
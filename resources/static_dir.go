@@ -0,0 +1,253 @@
+package resources
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"io/fs"
+	"mime"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/suramrit/hello-mcp/internal/registry"
+)
+
+// sniffLen is how much of a file we look at to guess its type - the same amount http.DetectContentType reads
+const sniffLen = 512
+
+// extraMIMETypes covers extensions the system MIME table often lacks
+// the table varies by OS, and a markdown file shouldn't turn into octet-stream on a slim container
+var extraMIMETypes = map[string]string{
+	".md":       "text/markdown",
+	".markdown": "text/markdown",
+	".txt":      "text/plain",
+	".json":     "application/json",
+	".yaml":     "application/yaml",
+	".yml":      "application/yaml",
+	".toml":     "application/toml",
+	".csv":      "text/csv",
+}
+
+// textMIMETypes are non-text/* types that are still text on the wire
+var textMIMETypes = map[string]bool{
+	"application/json":       true,
+	"application/yaml":       true,
+	"application/toml":       true,
+	"application/xml":        true,
+	"application/javascript": true,
+}
+
+// staticDirResource turns a directory tree into resources, one per file
+// drop a file in the directory and it's on the shelf next restart - no Go code needed
+type StaticDirResource struct {
+	fsys   fs.FS  // the tree to scan, e.g. Bundled() or main's Overlay on top of it
+	prefix string // URI prefix, e.g. "static://"; the file's slash path follows it
+}
+
+// newStaticDirResource creates a provider for fsys whose resources live under uriPrefix
+func NewStaticDirResource(fsys fs.FS, uriPrefix string) *StaticDirResource {
+	return &StaticDirResource{fsys: fsys, prefix: uriPrefix}
+}
+
+// scan walks the tree and describes every regular file in it
+// dotfiles and dot-directories are skipped, and so are symlinks - the catalog only lists what's really inside
+func (d *StaticDirResource) Scan() ([]*StaticFileResource, error) {
+	var files []*StaticFileResource
+	err := fs.WalkDir(d.fsys, ".", func(rel string, e fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if rel != "." && strings.HasPrefix(e.Name(), ".") {
+			if e.IsDir() {
+				return fs.SkipDir
+			}
+			return nil
+		}
+		if !e.Type().IsRegular() {
+			return nil
+		}
+
+		f, err := d.describe(rel)
+		if err != nil {
+			return err
+		}
+		files = append(files, f)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("scan static files: %w", err)
+	}
+	return files, nil
+}
+
+// register scans the tree and signs every file up with the resource registry
+// returns how many files were found; they all carry the "static" tag on top of opts
+func (d *StaticDirResource) Register(opts ...registry.Option) (int, error) {
+	files, err := d.Scan()
+	if err != nil {
+		return 0, err
+	}
+	opts = append([]registry.Option{registry.WithTags("static")}, opts...)
+	for _, f := range files {
+		Register(f, opts...)
+	}
+	return len(files), nil
+}
+
+// describe looks at one file once, up front, so resources/list never touches the disk
+func (d *StaticDirResource) describe(rel string) (*StaticFileResource, error) {
+	info, err := fs.Stat(d.fsys, rel)
+	if err != nil {
+		return nil, err
+	}
+	head, err := readHead(d.fsys, rel)
+	if err != nil {
+		return nil, err
+	}
+
+	mimeType := detectMIMEType(rel, head)
+	return &StaticFileResource{
+		fsys:     d.fsys,
+		rel:      rel,
+		uri:      d.prefix + escapePath(rel),
+		mimeType: mimeType,
+		text:     isText(mimeType, head),
+		size:     info.Size(),
+		title:    title(rel, mimeType, head),
+	}, nil
+}
+
+// staticFileResource is one file found by StaticDirResource
+type StaticFileResource struct {
+	fsys     fs.FS  // where it's read from - on disk or compiled in
+	rel      string // slash path below the scanned root
+	uri      string
+	mimeType string
+	text     bool // served as text rather than base64
+	size     int64
+	title    string
+}
+
+// getResource describes the file; this SDK version has no size or title fields,
+// so the title becomes the name and the size rides along in _meta
+func (f *StaticFileResource) GetResource() mcp.Resource {
+	r := mcp.NewResource(f.uri, f.title,
+		mcp.WithResourceDescription(fmt.Sprintf("%s (%d bytes)", f.rel, f.size)),
+		mcp.WithMIMEType(f.mimeType))
+	r.Meta = &mcp.Meta{AdditionalFields: map[string]any{"size": f.size, "path": f.rel}}
+	return r
+}
+
+// getHandler reads the file fresh on every call, so edits to an override show up without a restart
+func (f *StaticFileResource) GetHandler() server.ResourceHandlerFunc {
+	return func(ctx context.Context, req mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+		b, err := fs.ReadFile(f.fsys, f.rel)
+		if err != nil {
+			return nil, err
+		}
+
+		return []mcp.ResourceContents{fileContents(f.uri, f.mimeType, f.text, b)}, nil
+	}
+}
+
+// stat lets subscribers hear when the file changes
+func (f *StaticFileResource) Stat(uri string) (fs.FileInfo, error) {
+	return fs.Stat(f.fsys, f.rel)
+}
+
+// fileContents packages a file's bytes as text when it is text, and as base64 otherwise
+// a text file that grew some binary since we last looked still goes out intact
+func fileContents(uri, mimeType string, text bool, b []byte) mcp.ResourceContents {
+	if text && utf8.Valid(b) {
+		return mcp.TextResourceContents{URI: uri, MIMEType: mimeType, Text: string(b)}
+	}
+	return mcp.BlobResourceContents{URI: uri, MIMEType: mimeType, Blob: base64.StdEncoding.EncodeToString(b)}
+}
+
+// readHead returns the first sniffLen bytes of a file
+func readHead(fsys fs.FS, name string) ([]byte, error) {
+	fh, err := fsys.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer fh.Close()
+
+	head := make([]byte, sniffLen)
+	n, err := io.ReadFull(fh, head)
+	if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+		return nil, err
+	}
+	return head[:n], nil
+}
+
+// detectMIMEType goes by extension first and falls back to sniffing the content
+// parameters like "; charset=utf-8" are dropped - clients compare the bare type
+func detectMIMEType(rel string, head []byte) string {
+	ext := strings.ToLower(path.Ext(rel))
+	t := extraMIMETypes[ext]
+	if t == "" {
+		t = mime.TypeByExtension(ext)
+	}
+	if t == "" {
+		t = http.DetectContentType(head)
+	}
+	if mt, _, err := mime.ParseMediaType(t); err == nil {
+		return mt
+	}
+	return t
+}
+
+// isText decides between TextResourceContents and BlobResourceContents
+// an unknown type still counts as text when its first bytes are valid UTF-8 without NULs
+func isText(mimeType string, head []byte) bool {
+	if strings.HasPrefix(mimeType, "text/") || textMIMETypes[mimeType] || strings.HasSuffix(mimeType, "+json") || strings.HasSuffix(mimeType, "+xml") {
+		return true
+	}
+	if mimeType != "application/octet-stream" {
+		return false // images, archives and friends
+	}
+	if len(head) == sniffLen {
+		head = trimPartialRune(head)
+	}
+	return utf8.Valid(head) && !bytes.ContainsRune(head, 0)
+}
+
+// trimPartialRune drops a multi-byte character cut in half at the end of a sniffed prefix
+func trimPartialRune(b []byte) []byte {
+	for i := 0; i < utf8.UTFMax && i < len(b); i++ {
+		if utf8.Valid(b[:len(b)-i]) {
+			return b[:len(b)-i]
+		}
+	}
+	return b
+}
+
+// title is what a human would call the file: a markdown file's first heading, otherwise its name
+func title(rel, mimeType string, head []byte) string {
+	if mimeType == "text/markdown" {
+		sc := bufio.NewScanner(bytes.NewReader(head))
+		for sc.Scan() {
+			if h, ok := strings.CutPrefix(strings.TrimSpace(sc.Text()), "# "); ok && strings.TrimSpace(h) != "" {
+				return strings.TrimSpace(h)
+			}
+		}
+	}
+	return path.Base(rel)
+}
+
+// escapePath makes a slash path safe for a URI while keeping the slashes, so URIs stay readable
+func escapePath(rel string) string {
+	parts := strings.Split(rel, "/")
+	for i, p := range parts {
+		parts[i] = url.PathEscape(p)
+	}
+	return strings.Join(parts, "/")
+}
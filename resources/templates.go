@@ -0,0 +1,45 @@
+package resources
+
+import (
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/suramrit/hello-mcp/internal/registry"
+)
+
+// resourceTemplate is the companion of Resource for parameterized URIs like "docs://{section}"
+// one template answers for a whole family of books instead of a single catalog number
+type ResourceTemplate interface {
+	GetTemplate() mcp.ResourceTemplate              // which URIs do you answer? (RFC 6570 template, name, type)
+	GetHandler() server.ResourceTemplateHandlerFunc // how do we read one of them? variables come in via URIVar
+}
+
+// templateRegistry holds every resource template, keyed by its URI template
+var templateRegistry = registry.New[ResourceTemplate]("resource template")
+
+// registerTemplate adds a template to the registry under its URI template
+func RegisterTemplate(t ResourceTemplate, opts ...registry.Option) {
+	name := ""
+	if tmpl := t.GetTemplate().URITemplate; tmpl != nil {
+		name = tmpl.Raw()
+	}
+	templateRegistry.Add(name, t, opts...)
+}
+
+// registeredTemplates returns all registered templates sorted by URI template
+func RegisteredTemplates() ([]registry.Entry[ResourceTemplate], error) {
+	return templateRegistry.Entries()
+}
+
+// uriVar returns one variable matched out of the requested URI, e.g. "section" for "docs://{section}"
+// the SDK hands variables over as lists; list-valued ones ("{/path*}") come back comma-joined
+func URIVar(req mcp.ReadResourceRequest, name string) (string, bool) {
+	switch v := req.Params.Arguments[name].(type) {
+	case string:
+		return v, true
+	case []string:
+		return strings.Join(v, ","), len(v) > 0
+	}
+	return "", false
+}
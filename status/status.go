@@ -0,0 +1,135 @@
+package status
+
+import (
+	"runtime"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/suramrit/hello-mcp/middleware"
+)
+
+// startedAt is when the process came to life - close enough to server start for uptime
+var startedAt = time.Now()
+
+// state is what main tells us at startup; everything else we can work out ourselves
+var state = struct {
+	sync.RWMutex
+	name, version string
+	tools         []string
+	resources     []string
+	templates     []string
+	prompts       []string
+}{}
+
+// report is the full health picture we hand to clients
+// it's the server's annual checkup, available on demand
+type Report struct {
+	Server       ServerInfo             `json:"server"`
+	Capabilities Capabilities           `json:"capabilities"`
+	Metrics      map[string]interface{} `json:"metrics"`
+	Runtime      RuntimeStats           `json:"runtime"`
+}
+
+// serverInfo identifies the server and how long it's been up
+type ServerInfo struct {
+	Name          string    `json:"name"`
+	Version       string    `json:"version"`
+	StartedAt     time.Time `json:"started_at"`
+	UptimeSeconds float64   `json:"uptime_seconds"`
+}
+
+// capabilities lists what actually got registered (after config filtering)
+type Capabilities struct {
+	Tools             []string `json:"tools"`
+	Resources         []string `json:"resources"`
+	ResourceTemplates []string `json:"resource_templates"`
+	Prompts           []string `json:"prompts"`
+}
+
+// runtimeStats is the Go runtime's side of the story
+type RuntimeStats struct {
+	GoVersion      string `json:"go_version"`
+	Goroutines     int    `json:"goroutines"`
+	NumCPU         int    `json:"num_cpu"`
+	HeapAllocBytes uint64 `json:"heap_alloc_bytes"`
+	SysBytes       uint64 `json:"sys_bytes"`
+	NumGC          uint32 `json:"num_gc"`
+}
+
+// setServerInfo records the name and version we advertise to clients
+func SetServerInfo(name, version string) {
+	state.Lock()
+	defer state.Unlock()
+	state.name, state.version = name, version
+}
+
+// recordTool notes that a tool was registered with the MCP server
+func RecordTool(name string) {
+	state.Lock()
+	defer state.Unlock()
+	state.tools = append(state.tools, name)
+}
+
+// recordResource notes that a resource was registered with the MCP server
+func RecordResource(uri string) {
+	state.Lock()
+	defer state.Unlock()
+	state.resources = append(state.resources, uri)
+}
+
+// recordResourceTemplate notes that a resource template was registered with the MCP server
+func RecordResourceTemplate(uriTemplate string) {
+	state.Lock()
+	defer state.Unlock()
+	state.templates = append(state.templates, uriTemplate)
+}
+
+// recordPrompt notes that a prompt was registered with the MCP server
+func RecordPrompt(name string) {
+	state.Lock()
+	defer state.Unlock()
+	state.prompts = append(state.prompts, name)
+}
+
+// snapshot gathers everything into one report
+// runtime.ReadMemStats briefly stops the world, which is fine at human request rates
+func Snapshot() Report {
+	var mem runtime.MemStats
+	runtime.ReadMemStats(&mem)
+
+	state.RLock()
+	defer state.RUnlock()
+
+	return Report{
+		Server: ServerInfo{
+			Name:          state.name,
+			Version:       state.version,
+			StartedAt:     startedAt,
+			UptimeSeconds: time.Since(startedAt).Seconds(),
+		},
+		Capabilities: Capabilities{
+			Tools:             sortedCopy(state.tools),
+			Resources:         sortedCopy(state.resources),
+			ResourceTemplates: sortedCopy(state.templates),
+			Prompts:           sortedCopy(state.prompts),
+		},
+		Metrics: middleware.GlobalMetrics.GetStats(),
+		Runtime: RuntimeStats{
+			GoVersion:      runtime.Version(),
+			Goroutines:     runtime.NumGoroutine(),
+			NumCPU:         runtime.NumCPU(),
+			HeapAllocBytes: mem.HeapAlloc,
+			SysBytes:       mem.Sys,
+			NumGC:          mem.NumGC,
+		},
+	}
+}
+
+// sortedCopy keeps callers from mutating our state and keeps the output stable
+func sortedCopy(in []string) []string {
+	out := make([]string, len(in))
+	copy(out, in)
+	sort.Strings(out)
+	return out
+}
@@ -0,0 +1,79 @@
+package subscriptions
+
+import (
+	"bytes"
+	"encoding/json"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// rewriteMarker is the _meta key a rewritten request carries its original method in
+const rewriteMarker = "hello-mcp/subscription"
+
+// the SDK names most methods, but not these two
+const (
+	methodSubscribe   = "resources/subscribe"
+	methodUnsubscribe = "resources/unsubscribe"
+)
+
+// rewrite turns resources/subscribe and resources/unsubscribe into pings the SDK can answer
+// the SDK rejects methods it doesn't know, but a ping answers with the same empty result
+// subscribe wants - so the request is relabelled on its way in, the manager's hook does the
+// real work (or fails the request), and the SDK sends the reply through the usual channel
+// every other message passes through untouched
+func Rewrite(msg []byte) []byte {
+	if !bytes.Contains(msg, []byte(`"resources/`)) {
+		return msg // cheap check first - this runs on every message
+	}
+
+	var req struct {
+		JSONRPC string          `json:"jsonrpc"`
+		ID      json.RawMessage `json:"id"`
+		Method  string          `json:"method"`
+		Params  struct {
+			URI string `json:"uri"`
+		} `json:"params"`
+	}
+	if json.Unmarshal(msg, &req) != nil || req.ID == nil {
+		return msg
+	}
+	if req.Method != methodSubscribe && req.Method != methodUnsubscribe {
+		return msg
+	}
+
+	out, err := json.Marshal(map[string]any{
+		"jsonrpc": req.JSONRPC,
+		"id":      req.ID,
+		"method":  mcp.MethodPing,
+		"params": map[string]any{
+			"_meta": map[string]any{rewriteMarker: req.Method},
+			"uri":   req.Params.URI,
+		},
+	})
+	if err != nil {
+		return msg
+	}
+	return out
+}
+
+// parseRewritten recognises a ping made by Rewrite and returns what it stands for
+func parseRewritten(raw json.RawMessage) (op, uri string, ok bool) {
+	if !bytes.Contains(raw, []byte(rewriteMarker)) {
+		return "", "", false
+	}
+	var req struct {
+		Method string `json:"method"`
+		Params struct {
+			Meta map[string]any `json:"_meta"`
+			URI  string         `json:"uri"`
+		} `json:"params"`
+	}
+	if json.Unmarshal(raw, &req) != nil || req.Method != string(mcp.MethodPing) {
+		return "", "", false
+	}
+	method, _ := req.Params.Meta[rewriteMarker].(string)
+	if method == "" {
+		return "", "", false
+	}
+	return method, req.Params.URI, true
+}
@@ -0,0 +1,243 @@
+package subscriptions
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// stater is implemented by resources backed by a file; a change in what Stat returns means the content changed
+// fixed resources ignore uri, templates use it to find the file it names
+type Stater interface {
+	Stat(uri string) (fs.FileInfo, error)
+}
+
+// options tunes how changes are noticed
+type Options struct {
+	PollInterval time.Duration // how often watched files are looked at
+	Debounce     time.Duration // how long a file has to sit still before subscribers hear about it
+
+	// authorize is the policy check for subscribing to a URI; nil allows everything
+	// point it at the same policy reads go through, so subscribing can't see more than reading
+	Authorize func(ctx context.Context, uri string) bool
+}
+
+// manager remembers which session is subscribed to which resource and tells them when it changes
+// the SDK has no subscribe support of its own - see Rewrite for how the requests reach us
+type Manager struct {
+	srv  *server.MCPServer
+	opts Options
+
+	mu        sync.Mutex
+	fixed     map[string]Stater // watchable resources by URI
+	templates []watchedTemplate
+	subs      map[string]*subscription // by URI
+	sessions  map[string]time.Time     // when each subscribed session last heard from us (or us from it)
+}
+
+// watchedTemplate is a file-backed resource template; a subscription to any URI it matches is watchable
+type watchedTemplate struct {
+	tmpl *mcp.URITemplate
+	stat Stater
+}
+
+// subscription is everyone watching one URI, plus what the file looked like when we last checked
+type subscription struct {
+	stat     Stater
+	sessions map[string]struct{}
+	seen     fingerprint
+	changed  time.Time // when seen last changed; zero while nothing is waiting to be sent
+}
+
+// fingerprint is the cheap version of "did the content change"
+type fingerprint struct {
+	exists  bool
+	size    int64
+	modTime time.Time
+}
+
+// new creates a manager that sends notifications through srv
+func New(srv *server.MCPServer, opts Options) *Manager {
+	return &Manager{
+		srv:      srv,
+		opts:     opts,
+		fixed:    make(map[string]Stater),
+		subs:     make(map[string]*subscription),
+		sessions: make(map[string]time.Time),
+	}
+}
+
+// watch makes a fixed resource subscribable; call it while registering resources
+func (m *Manager) Watch(uri string, s Stater) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.fixed[uri] = s
+}
+
+// watchTemplate makes every URI a template matches subscribable
+func (m *Manager) WatchTemplate(tmpl *mcp.URITemplate, s Stater) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.templates = append(m.templates, watchedTemplate{tmpl: tmpl, stat: s})
+}
+
+// addHooks wires the manager into the server: subscribe/unsubscribe requests and session cleanup
+func (m *Manager) AddHooks(hooks *server.Hooks) {
+	hooks.AddOnRequestInitialization(m.onRequest)
+	hooks.AddOnUnregisterSession(func(ctx context.Context, session server.ClientSession) {
+		// a streamable HTTP session outlives its GET stream - it ends with DELETE, which calls EndSession
+		if _, streamable := session.(server.SessionWithStreamableHTTPConfig); streamable {
+			return
+		}
+		m.EndSession(session.SessionID())
+	})
+}
+
+// onRequest picks out the pings Rewrite made from subscribe and unsubscribe requests
+// returning an error here fails the request, which is how a bad subscription gets its answer
+func (m *Manager) onRequest(ctx context.Context, id any, message any) error {
+	raw, ok := message.(json.RawMessage)
+	if !ok {
+		return nil
+	}
+	op, uri, ok := parseRewritten(raw)
+	if !ok {
+		return nil
+	}
+
+	session := server.ClientSessionFromContext(ctx)
+	if session == nil {
+		return errors.New("subscriptions need a session")
+	}
+	switch op {
+	case methodSubscribe:
+		return m.subscribe(ctx, session.SessionID(), uri)
+	case methodUnsubscribe:
+		m.unsubscribe(session.SessionID(), uri)
+	}
+	return nil
+}
+
+// subscribe starts telling a session about changes to uri
+func (m *Manager) subscribe(ctx context.Context, sessionID, uri string) error {
+	if uri == "" {
+		return errors.New("resources/subscribe needs a uri")
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	sub, ok := m.subs[uri]
+	if !ok {
+		stat, err := m.stater(ctx, uri)
+		if err != nil {
+			return err
+		}
+		sub = &subscription{stat: stat, sessions: make(map[string]struct{}), seen: look(stat, uri)}
+		m.subs[uri] = sub
+	} else if !m.allowed(ctx, uri) {
+		return fmt.Errorf("resource %s not found", uri)
+	}
+
+	sub.sessions[sessionID] = struct{}{}
+	m.sessions[sessionID] = time.Now()
+	slog.InfoContext(ctx, "resource subscribed", "component", "subscriptions", "resource", uri, "session", sessionID)
+	return nil
+}
+
+// unsubscribe stops telling a session about uri; unknown subscriptions are fine, the result is the same
+func (m *Manager) unsubscribe(sessionID, uri string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if sub, ok := m.subs[uri]; ok {
+		delete(sub.sessions, sessionID)
+		if len(sub.sessions) == 0 {
+			delete(m.subs, uri)
+		}
+	}
+	m.forgetIfIdle(sessionID)
+}
+
+// endSession drops every subscription a session held
+func (m *Manager) EndSession(sessionID string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.dropSession(sessionID)
+}
+
+// dropSession is EndSession for callers already holding m.mu
+func (m *Manager) dropSession(sessionID string) {
+	if _, ok := m.sessions[sessionID]; !ok {
+		return
+	}
+	for uri, sub := range m.subs {
+		delete(sub.sessions, sessionID)
+		if len(sub.sessions) == 0 {
+			delete(m.subs, uri)
+		}
+	}
+	delete(m.sessions, sessionID)
+	slog.Info("subscriptions dropped for ended session", "component", "subscriptions", "session", sessionID)
+}
+
+// forgetIfIdle stops tracking a session once it holds no subscriptions
+func (m *Manager) forgetIfIdle(sessionID string) {
+	for _, sub := range m.subs {
+		if _, ok := sub.sessions[sessionID]; ok {
+			return
+		}
+	}
+	delete(m.sessions, sessionID)
+}
+
+// stater finds what backs uri, refusing URIs that aren't file-backed or that the caller may not read
+// both get the same answer, so subscribing can't be used to probe for hidden resources
+func (m *Manager) stater(ctx context.Context, uri string) (Stater, error) {
+	notFound := fmt.Errorf("resource %s not found or not subscribable", uri)
+	if !m.allowed(ctx, uri) {
+		return nil, notFound
+	}
+	if s, ok := m.fixed[uri]; ok {
+		return s, nil
+	}
+	for _, t := range m.templates {
+		if t.tmpl.Regexp().MatchString(uri) {
+			if _, err := t.stat.Stat(uri); err != nil && !errors.Is(err, fs.ErrNotExist) {
+				return nil, notFound // outside the sandbox, or not a path at all
+			}
+			return t.stat, nil
+		}
+	}
+	return nil, notFound
+}
+
+// allowed applies the authorization policy the way reads do: the URI itself, or a template matching it
+func (m *Manager) allowed(ctx context.Context, uri string) bool {
+	if m.opts.Authorize == nil || m.opts.Authorize(ctx, uri) {
+		return true
+	}
+	for _, t := range m.templates {
+		if t.tmpl.Regexp().MatchString(uri) && m.opts.Authorize(ctx, t.tmpl.Raw()) {
+			return true
+		}
+	}
+	return false
+}
+
+// look takes a fingerprint; a file that's missing is a state too, so deleting and recreating it both count
+func look(s Stater, uri string) fingerprint {
+	info, err := s.Stat(uri)
+	if err != nil {
+		return fingerprint{}
+	}
+	return fingerprint{exists: true, size: info.Size(), modTime: info.ModTime()}
+}
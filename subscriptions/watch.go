@@ -0,0 +1,79 @@
+package subscriptions
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// staleAfter is how long a session can go unreachable before its subscriptions are dropped
+// streamable HTTP clients that vanish without a DELETE would otherwise be watched forever
+const staleAfter = 30 * time.Minute
+
+// run polls the subscribed files until ctx is done
+// polling beats OS file watchers here: it's portable, survives editors that replace files
+// instead of writing them, and only ever looks at files someone cares about
+func (m *Manager) Run(ctx context.Context) {
+	ticker := time.NewTicker(m.opts.PollInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			m.poll(now)
+		}
+	}
+}
+
+// notification is one resources/updated message waiting to go out
+type notification struct {
+	uri, sessionID string
+}
+
+// poll looks at every subscribed file once and sends what's due
+// a burst of writes keeps pushing changed forward, so subscribers hear once it's over
+func (m *Manager) poll(now time.Time) {
+	m.mu.Lock()
+	var due []notification
+	for uri, sub := range m.subs {
+		if fp := look(sub.stat, uri); fp != sub.seen {
+			sub.seen, sub.changed = fp, now
+			continue
+		}
+		if !sub.changed.IsZero() && now.Sub(sub.changed) >= m.opts.Debounce {
+			sub.changed = time.Time{}
+			for sessionID := range sub.sessions {
+				due = append(due, notification{uri: uri, sessionID: sessionID})
+			}
+		}
+	}
+	m.mu.Unlock()
+
+	// send outside the lock - a slow client shouldn't hold up subscribe requests
+	for _, n := range due {
+		m.notify(n, now)
+	}
+}
+
+// notify tells one session that uri changed
+func (m *Manager) notify(n notification, now time.Time) {
+	err := m.srv.SendNotificationToSpecificClient(n.sessionID, mcp.MethodNotificationResourceUpdated, map[string]any{"uri": n.uri})
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	switch {
+	case err == nil:
+		m.sessions[n.sessionID] = now
+		slog.Debug("resource update sent", "component", "subscriptions", "resource", n.uri, "session", n.sessionID)
+	case errors.Is(err, server.ErrSessionNotFound) && now.Sub(m.sessions[n.sessionID]) > staleAfter:
+		m.dropSession(n.sessionID)
+	default:
+		// a streamable HTTP client without its GET stream open can't be reached right now; it may come back
+		slog.Warn("resource update not delivered", "component", "subscriptions", "resource", n.uri, "session", n.sessionID, "error", err)
+	}
+}
@@ -2,12 +2,20 @@ package tools
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 
 	mcp "github.com/mark3labs/mcp-go/mcp"
 	server "github.com/mark3labs/mcp-go/server"
+	"github.com/suramrit/hello-mcp/internal/registry"
+	"github.com/suramrit/hello-mcp/middleware"
 )
 
+// echo registers itself so main never has to know it exists
+func init() {
+	Register(NewEchoTool(), registry.WithTags("demo", "text"))
+}
+
 // newEchoTool creates our simple but lovable echo tool
 // it's like a friendly parrot that repeats what you say!
 func NewEchoTool() *EchoTool {
@@ -32,6 +40,17 @@ func (t *EchoTool) GetTool() mcp.Tool {
 	)
 }
 
+// annotations tell clients echo is harmless - it reads nothing, changes nothing, and says the same thing twice
+func (t *EchoTool) Annotations() mcp.ToolAnnotation {
+	return mcp.ToolAnnotation{
+		Title:           "Echo",
+		ReadOnlyHint:    mcp.ToBoolPtr(true),
+		DestructiveHint: mcp.ToBoolPtr(false),
+		IdempotentHint:  mcp.ToBoolPtr(true),
+		OpenWorldHint:   mcp.ToBoolPtr(false),
+	}
+}
+
 // getHandler returns the actual function that does the work
 // this is where the rubber meets the road!
 func (t *EchoTool) GetHandler() server.ToolHandlerFunc {
@@ -39,11 +58,29 @@ func (t *EchoTool) GetHandler() server.ToolHandlerFunc {
 		// extract the name parameter - this could fail if AI misbehaves
 		name, err := req.RequireString("name")
 		if err != nil {
-			// return a nice error message instead of crashing
-			return mcp.NewToolResultError(err.Error()), nil
+			// return a nice error message instead of crashing - and let the dashboards know it was the caller's fault
+			return middleware.InvalidArguments(ctx, err), nil
 		}
 
 		// do our incredibly sophisticated work: say hello!
-		return mcp.NewToolResultText(fmt.Sprintf("Hello, %s!", name)), nil
+		// agents get a field to read, humans (and older clients) get the same words as text
+		greeting := fmt.Sprintf("Hello, %s!", name)
+		return mcp.NewToolResultStructured(echoOutput{Greeting: greeting}, greeting), nil
 	}
 }
+
+// echoOutput is the structured half of the echo result
+type echoOutput struct {
+	Greeting string `json:"greeting"`
+}
+
+// outputSchema describes echoOutput for clients that want to parse our answer
+func (t *EchoTool) OutputSchema() json.RawMessage {
+	return json.RawMessage(`{
+		"type": "object",
+		"properties": {
+			"greeting": {"type": "string", "description": "The friendly hello"}
+		},
+		"required": ["greeting"]
+	}`)
+}
@@ -0,0 +1,21 @@
+package tools
+
+import (
+	"github.com/suramrit/hello-mcp/internal/registry"
+)
+
+// toolRegistry holds every tool that has registered itself
+// tools call Register from their own init(), so adding one never touches main.go
+var toolRegistry = registry.New[Tool]("tool")
+
+// register adds a tool to the registry under its declared name
+// call it from init() in the tool's own file
+func Register(tool Tool, opts ...registry.Option) {
+	toolRegistry.Add(tool.GetTool().Name, tool, opts...)
+}
+
+// registered returns all registered tools sorted by name
+// an error means two tools claimed the same name - fix that before starting!
+func Registered() ([]registry.Entry[Tool], error) {
+	return toolRegistry.Entries()
+}
@@ -0,0 +1,56 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+
+	mcp "github.com/mark3labs/mcp-go/mcp"
+	server "github.com/mark3labs/mcp-go/server"
+	"github.com/suramrit/hello-mcp/internal/registry"
+	"github.com/suramrit/hello-mcp/status"
+)
+
+// server_stats lets a connected agent check on the server's health
+func init() {
+	Register(NewServerStatsTool(), registry.WithTags("ops"))
+}
+
+// newServerStatsTool creates the health-check tool
+func NewServerStatsTool() *ServerStatsTool {
+	return &ServerStatsTool{}
+}
+
+// serverStatsTool reports metrics, uptime, capabilities and runtime stats
+// it's the server's vital signs monitor, readable from inside the conversation
+type ServerStatsTool struct {
+	// nothing to configure - all the data lives in the status package
+}
+
+// getTool describes the tool - no arguments, just ask and it tells you
+func (t *ServerStatsTool) GetTool() mcp.Tool {
+	return mcp.NewTool("server_stats",
+		mcp.WithDescription("Report server health: uptime, version, registered capabilities, call metrics and Go runtime stats"),
+	)
+}
+
+// annotations: looking at the stats never changes them (well, apart from this call's own counters)
+func (t *ServerStatsTool) Annotations() mcp.ToolAnnotation {
+	return mcp.ToolAnnotation{
+		Title:           "Server stats",
+		ReadOnlyHint:    mcp.ToBoolPtr(true),
+		DestructiveHint: mcp.ToBoolPtr(false),
+		IdempotentHint:  mcp.ToBoolPtr(true),
+		OpenWorldHint:   mcp.ToBoolPtr(false),
+	}
+}
+
+// getHandler returns the stats as pretty-printed JSON
+func (t *ServerStatsTool) GetHandler() server.ToolHandlerFunc {
+	return func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		b, err := json.MarshalIndent(status.Snapshot(), "", "  ")
+		if err != nil {
+			return nil, err // the logging middleware turns this into a proper tool error
+		}
+		return mcp.NewToolResultText(string(b)), nil
+	}
+}
@@ -1,6 +1,9 @@
 package tools
 
 import (
+	"encoding/json"
+	"time"
+
 	mcp "github.com/mark3labs/mcp-go/mcp"
 	server "github.com/mark3labs/mcp-go/server"
 )
@@ -11,3 +14,64 @@ type Tool interface {
 	GetTool() mcp.Tool                  // tell us what you are (name, description, parameters)
 	GetHandler() server.ToolHandlerFunc // show us what you can do (the actual implementation)
 }
+
+// timeoutTool is an optional extra for tools that know how long they should take
+// the middleware cancels the handler's context once the deadline passes;
+// config can still override it per tool
+type TimeoutTool interface {
+	Timeout() time.Duration
+}
+
+// sensitiveTool is an optional extra for tools that take secrets or private data
+// return argument names or glob patterns (e.g. "api_key", "*_token") and their values
+// will be masked in the logs - the handler still receives them untouched
+type SensitiveTool interface {
+	SensitiveArguments() []string
+}
+
+// structuredTool is an optional extra for tools whose results are machine-readable
+// return a JSON schema (type "object") describing the result's structured content; registration
+// copies it into the tool definition unless GetTool already declares one (mcp.WithOutputSchema).
+// such tools answer with mcp.NewToolResultStructured(value, text) - the value for agents,
+// the text for clients that predate structured content
+type StructuredTool interface {
+	OutputSchema() json.RawMessage
+}
+
+// annotatedTool is an optional extra for declaring how a tool behaves, so clients can decide
+// which calls need a human to confirm them. only the hints you set override the SDK defaults,
+// which assume the worst: not read-only, destructive, not idempotent, open-world.
+// destructive tools are refused unless the config allows them
+type AnnotatedTool interface {
+	Annotations() mcp.ToolAnnotation
+}
+
+// definition returns a tool's full definition: GetTool plus whatever the optional extras add
+// anything GetTool sets itself wins over the extras
+func Definition(t Tool) mcp.Tool {
+	def := t.GetTool()
+
+	if s, ok := t.(StructuredTool); ok && def.RawOutputSchema == nil && def.OutputSchema.Type == "" {
+		def.RawOutputSchema = s.OutputSchema()
+	}
+
+	if a, ok := t.(AnnotatedTool); ok {
+		hints := a.Annotations()
+		if hints.Title != "" {
+			def.Annotations.Title = hints.Title
+		}
+		if hints.ReadOnlyHint != nil {
+			def.Annotations.ReadOnlyHint = hints.ReadOnlyHint
+		}
+		if hints.DestructiveHint != nil {
+			def.Annotations.DestructiveHint = hints.DestructiveHint
+		}
+		if hints.IdempotentHint != nil {
+			def.Annotations.IdempotentHint = hints.IdempotentHint
+		}
+		if hints.OpenWorldHint != nil {
+			def.Annotations.OpenWorldHint = hints.OpenWorldHint
+		}
+	}
+	return def
+}
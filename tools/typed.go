@@ -0,0 +1,78 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	mcp "github.com/mark3labs/mcp-go/mcp"
+	server "github.com/mark3labs/mcp-go/server"
+	"github.com/suramrit/hello-mcp/middleware"
+)
+
+// typedFunc is a tool written as a plain Go function: structs in, struct out
+// In and Out use ordinary json tags, plus jsonschema tags for descriptions, enums and limits:
+//
+//	type GreetIn struct {
+//		Name string `json:"name" jsonschema:"description=Who to greet,minLength=1"`
+//	}
+type TypedFunc[In, Out any] func(ctx context.Context, in In) (Out, error)
+
+// typedTool adapts a TypedFunc to the Tool interface
+// the input and output schemas come from the struct types, so they can't drift from the code
+type TypedTool[In, Out any] struct {
+	tool mcp.Tool
+	fn   TypedFunc[In, Out]
+}
+
+// newTyped builds a tool from a function; extra options (descriptions, annotations...) apply on top
+// Out should be a struct - MCP output schemas always describe an object
+// a typed tool gets the SDK's worst-case hints, so it counts as destructive and is refused unless
+// allow_destructive_tools is on - pass mcp.WithReadOnlyHintAnnotation(true) if it only reads
+func NewTyped[In, Out any](name, description string, fn TypedFunc[In, Out], opts ...mcp.ToolOption) *TypedTool[In, Out] {
+	opts = append([]mcp.ToolOption{
+		mcp.WithDescription(description),
+		mcp.WithInputSchema[In](),
+		mcp.WithOutputSchema[Out](),
+	}, opts...)
+	return &TypedTool[In, Out]{tool: mcp.NewTool(name, opts...), fn: fn}
+}
+
+// getTool returns the definition with both generated schemas
+func (t *TypedTool[In, Out]) GetTool() mcp.Tool {
+	return t.tool
+}
+
+// getHandler decodes the arguments, calls the function and packs up the result
+// agents read the structured content; older clients get the same thing as text
+func (t *TypedTool[In, Out]) GetHandler() server.ToolHandlerFunc {
+	return func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		var in In
+		if err := req.BindArguments(&in); err != nil {
+			return middleware.InvalidArguments(ctx, fmt.Errorf("invalid arguments: %w", err)), nil
+		}
+
+		out, err := t.fn(ctx, in)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil, err // cancelled or timed out - let the middleware say so
+			}
+			return mcp.NewToolResultError(err.Error()), nil // the tool ran and said no
+		}
+
+		return mcp.NewToolResultStructured(out, fallbackText(out)), nil
+	}
+}
+
+// fallbackText is what clients without structured content support see
+// a Stringer gets to speak for itself; anything else is shown as its JSON
+func fallbackText(out any) string {
+	if s, ok := out.(fmt.Stringer); ok {
+		return s.String()
+	}
+	b, err := json.Marshal(out)
+	if err != nil {
+		return fmt.Sprintf("%v", out)
+	}
+	return string(b)
+}
@@ -0,0 +1,110 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+// exporter picks where finished spans go
+type Exporter string
+
+const (
+	ExporterNone Exporter = ""     // tracing off - spans are never created
+	ExporterOTLP Exporter = "otlp" // OTLP over HTTP to a collector, Jaeger, Tempo and friends
+	ExporterFile Exporter = "file" // one JSON span per line in a local file, for when there's no collector
+)
+
+// options describes the tracer provider we install
+type Options struct {
+	Exporter       Exporter
+	Endpoint       string  // OTLP endpoint, "host:port" or a full URL; empty honours OTEL_EXPORTER_OTLP_ENDPOINT
+	Insecure       bool    // talk plain HTTP to the OTLP endpoint
+	File           string  // where the file exporter writes
+	SampleRatio    float64 // fraction of new traces to keep; traces started by the client follow its decision
+	ServiceName    string
+	ServiceVersion string
+}
+
+// parseExporter turns a user-supplied string into an Exporter
+func ParseExporter(s string) (Exporter, error) {
+	switch e := Exporter(s); e {
+	case ExporterNone, ExporterOTLP, ExporterFile:
+		return e, nil
+	default:
+		return "", fmt.Errorf("unknown trace exporter %q (want %s or %s)", s, ExporterOTLP, ExporterFile)
+	}
+}
+
+// setup installs a global tracer provider and the W3C propagators
+// the returned shutdown flushes buffered spans - call it before exiting or the last few are lost
+func Setup(ctx context.Context, opts Options) (shutdown func(context.Context) error, err error) {
+	if opts.Exporter == ExporterNone {
+		return func(context.Context) error { return nil }, nil
+	}
+
+	var (
+		exporter sdktrace.SpanExporter
+		file     *os.File
+	)
+	switch opts.Exporter {
+	case ExporterOTLP:
+		var clientOpts []otlptracehttp.Option
+		if strings.Contains(opts.Endpoint, "://") {
+			clientOpts = append(clientOpts, otlptracehttp.WithEndpointURL(opts.Endpoint))
+		} else if opts.Endpoint != "" {
+			clientOpts = append(clientOpts, otlptracehttp.WithEndpoint(opts.Endpoint))
+		}
+		if opts.Insecure {
+			clientOpts = append(clientOpts, otlptracehttp.WithInsecure())
+		}
+		exporter, err = otlptracehttp.New(ctx, clientOpts...)
+	case ExporterFile:
+		file, err = os.OpenFile(opts.File, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0666)
+		if err != nil {
+			return nil, fmt.Errorf("open trace file: %w", err)
+		}
+		exporter, err = stdouttrace.New(stdouttrace.WithWriter(file))
+	default:
+		return nil, fmt.Errorf("unknown trace exporter %q", opts.Exporter)
+	}
+	if err != nil {
+		if file != nil {
+			file.Close()
+		}
+		return nil, fmt.Errorf("create %s trace exporter: %w", opts.Exporter, err)
+	}
+
+	ratio := opts.SampleRatio
+	if ratio <= 0 || ratio > 1 {
+		ratio = 1 // the zero value keeps everything - sampling is an opt-in
+	}
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(ratio))),
+		sdktrace.WithResource(resource.NewSchemaless(
+			attribute.String("service.name", opts.ServiceName),
+			attribute.String("service.version", opts.ServiceVersion),
+		)),
+	)
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+
+	return func(ctx context.Context) error {
+		err := provider.Shutdown(ctx)
+		if file != nil {
+			err = errors.Join(err, file.Close())
+		}
+		return err
+	}, nil
+}
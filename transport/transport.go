@@ -0,0 +1,237 @@
+package transport
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// mode picks how we talk to the outside world
+// stdio for desktop clients, sse and http when we're running as a shared service
+type Mode string
+
+const (
+	ModeStdio          Mode = "stdio" // spawned as a subprocess, JSON-RPC over stdin/stdout
+	ModeSSE            Mode = "sse"   // the older server-sent events transport
+	ModeStreamableHTTP Mode = "http"  // the newer streamable HTTP transport
+)
+
+// options controls where and how the network transports listen
+// stdio ignores everything except the mode - it has nowhere to listen!
+type Options struct {
+	Mode            Mode          // which transport to run
+	Addr            string        // listen address for network transports, e.g. ":8080"
+	BasePath        string        // URL prefix the MCP endpoints live under, e.g. "/mcp"
+	ShutdownTimeout time.Duration // how long in-flight requests get to finish when we stop
+
+	// auth wraps the MCP endpoints of the network transports, e.g. auth.Authenticator.Middleware
+	// nil leaves them open to anyone who can reach the port; stdio never uses it
+	Auth func(http.Handler) http.Handler
+
+	// rewrite sees every incoming JSON-RPC message before the SDK does and may replace it,
+	// e.g. subscriptions.Rewrite; nil passes messages through as they are
+	Rewrite func([]byte) []byte
+
+	// onSessionEnd is called when a streamable HTTP client ends its session with DELETE
+	// the SDK has no hook for that, unlike SSE and stdio sessions, which unregister themselves
+	OnSessionEnd func(sessionID string)
+}
+
+// parseMode turns a user-supplied string into a Mode
+// we'd rather fail loudly at startup than silently fall back to stdio
+func ParseMode(s string) (Mode, error) {
+	switch m := Mode(s); m {
+	case ModeStdio, ModeSSE, ModeStreamableHTTP:
+		return m, nil
+	default:
+		return "", fmt.Errorf("unknown transport %q (want %s, %s or %s)", s, ModeStdio, ModeSSE, ModeStreamableHTTP)
+	}
+}
+
+// serve runs the MCP server on the selected transport until ctx is cancelled
+// every transport wraps the same MCPServer, so clients see identical capabilities
+func Serve(ctx context.Context, srv *server.MCPServer, opts Options) error {
+	switch opts.Mode {
+	case ModeStdio, "":
+		return serveStdio(ctx, srv, opts)
+	case ModeSSE:
+		return serveSSE(ctx, srv, opts)
+	case ModeStreamableHTTP:
+		return serveStreamableHTTP(ctx, srv, opts)
+	default:
+		return fmt.Errorf("unknown transport %q", opts.Mode)
+	}
+}
+
+// serveStdio listens for JSON-RPC over stdin/stdout
+// the MCP client (like Claude Desktop) spawns us and talks to us here
+func serveStdio(ctx context.Context, srv *server.MCPServer, opts Options) error {
+	stdio := server.NewStdioServer(srv)
+	stdio.SetErrorLogger(log.Default()) // keep SDK errors in our log file, not on stdout
+
+	var stdin io.Reader = os.Stdin
+	if opts.Rewrite != nil {
+		stdin = rewriteLines(os.Stdin, opts.Rewrite)
+	}
+	err := stdio.Listen(ctx, stdin, os.Stdout)
+	if errors.Is(err, context.Canceled) {
+		return nil // we were asked to stop - that's not a failure
+	}
+	return err
+}
+
+// serveSSE exposes the server over server-sent events
+// clients connect to <base>/sse and post messages to <base>/message
+func serveSSE(ctx context.Context, srv *server.MCPServer, opts Options) error {
+	httpSrv := &http.Server{Addr: opts.Addr}
+	sse := server.NewSSEServer(srv,
+		server.WithStaticBasePath(opts.BasePath),
+		server.WithHTTPServer(httpSrv), // lets sse.Shutdown close sessions and the listener together
+	)
+	httpSrv.Handler = withAuth(opts, withRewrite(opts, sse))
+
+	log.Printf("SSE transport listening on %s (endpoint %s)", opts.Addr, sse.CompleteSsePath())
+	return listenAndShutdown(ctx, httpSrv, sse.Shutdown, opts.ShutdownTimeout)
+}
+
+// serveStreamableHTTP exposes the server over the streamable HTTP transport
+// everything lives on a single endpoint at the base path
+func serveStreamableHTTP(ctx context.Context, srv *server.MCPServer, opts Options) error {
+	httpSrv := &http.Server{Addr: opts.Addr}
+	streamable := server.NewStreamableHTTPServer(srv,
+		server.WithEndpointPath(opts.BasePath),
+		server.WithStreamableHTTPServer(httpSrv),
+		server.WithSessionIdManager(&sessionEndNotifier{onEnd: opts.OnSessionEnd}),
+	)
+
+	mux := http.NewServeMux()
+	mux.Handle(opts.BasePath, withRewrite(opts, streamable))
+	httpSrv.Handler = withAuth(opts, mux)
+
+	log.Printf("Streamable HTTP transport listening on %s (endpoint %s)", opts.Addr, opts.BasePath)
+	return listenAndShutdown(ctx, httpSrv, streamable.Shutdown, opts.ShutdownTimeout)
+}
+
+// withAuth puts the configured auth layer, if any, in front of a transport's handler
+func withAuth(opts Options, h http.Handler) http.Handler {
+	if opts.Auth == nil {
+		return h
+	}
+	return opts.Auth(h)
+}
+
+// withRewrite applies opts.Rewrite to the JSON-RPC message in each POST body
+func withRewrite(opts Options, h http.Handler) http.Handler {
+	if opts.Rewrite == nil {
+		return h
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost && r.Body != nil {
+			body, err := io.ReadAll(r.Body)
+			r.Body.Close()
+			if err != nil {
+				http.Error(w, "could not read request body", http.StatusBadRequest)
+				return
+			}
+			body = opts.Rewrite(body)
+			r.Body = io.NopCloser(bytes.NewReader(body))
+			r.ContentLength = int64(len(body))
+		}
+		h.ServeHTTP(w, r)
+	})
+}
+
+// rewriteLines applies rewrite to each line of a newline-delimited JSON-RPC stream, like stdio's
+func rewriteLines(r io.Reader, rewrite func([]byte) []byte) io.Reader {
+	pr, pw := io.Pipe()
+	go func() {
+		br := bufio.NewReader(r)
+		for {
+			line, err := br.ReadBytes('\n')
+			if len(line) > 0 {
+				out := append(rewrite(bytes.TrimRight(line, "\r\n")), '\n')
+				if _, werr := pw.Write(out); werr != nil {
+					return // the reading side is gone
+				}
+			}
+			if err != nil {
+				pw.CloseWithError(err) // io.EOF reads as a clean end on the other side
+				return
+			}
+		}
+	}()
+	return pr
+}
+
+// sessionEndNotifier is the SDK's default session ID manager, plus a call when a session is terminated
+// the SDK's manager accepts any well-formed ID, so this one remembers what it handed out: a DELETE
+// naming a session it never issued, or one already gone, ends nothing (an ID costs a few bytes
+// until then, which beats letting anyone drop somebody else's subscriptions)
+type sessionEndNotifier struct {
+	server.InsecureStatefulSessionIdManager
+	onEnd func(sessionID string)
+	live  sync.Map // session ID -> struct{}, for every session issued and not yet terminated
+}
+
+func (s *sessionEndNotifier) Generate() string {
+	id := s.InsecureStatefulSessionIdManager.Generate()
+	s.live.Store(id, struct{}{})
+	return id
+}
+
+func (s *sessionEndNotifier) Terminate(sessionID string) (bool, error) {
+	notAllowed, err := s.InsecureStatefulSessionIdManager.Terminate(sessionID)
+	if err != nil || notAllowed {
+		return notAllowed, err
+	}
+	if _, ok := s.live.LoadAndDelete(sessionID); ok && s.onEnd != nil {
+		s.onEnd(sessionID)
+	}
+	return false, nil
+}
+
+// serveHandler runs a plain HTTP side server (metrics, health...) until ctx is done
+// it gets the same graceful shutdown as the MCP transports
+func ServeHandler(ctx context.Context, addr string, handler http.Handler, shutdownTimeout time.Duration) error {
+	httpSrv := &http.Server{Addr: addr, Handler: handler}
+	return listenAndShutdown(ctx, httpSrv, httpSrv.Shutdown, shutdownTimeout)
+}
+
+// listenAndShutdown serves until ctx is done, then gives in-flight requests
+// a grace period to finish before pulling the plug
+func listenAndShutdown(ctx context.Context, httpSrv *http.Server, shutdown func(context.Context) error, timeout time.Duration) error {
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- httpSrv.ListenAndServe()
+	}()
+
+	select {
+	case err := <-errCh:
+		// the listener died on its own (port in use, etc.) - nothing to shut down
+		return err
+	case <-ctx.Done():
+	}
+
+	log.Printf("Shutting down transport (grace period %v)...", timeout)
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	if err := shutdown(shutdownCtx); err != nil {
+		return fmt.Errorf("graceful shutdown failed: %w", err)
+	}
+
+	// ListenAndServe returns ErrServerClosed once Shutdown is called - that's the happy path
+	if err := <-errCh; !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}